@@ -18,15 +18,18 @@ package cronexpr
 /******************************************************************************/
 
 import (
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 )
 
 /******************************************************************************/
 
 // A Expression represents a specific cron time expression as defined at
-// <https://github.com/gorhill/cronexpr#implementation>
+// <https://github.com/gorhill/cronexpr#implementation>. It is not modified once
+// parsed, and is safe for concurrent use.
 type Expression struct {
 	expression             string
 	secondList             []int
@@ -37,7 +40,6 @@ type Expression struct {
 	lastDayOfMonth         bool
 	lastWorkdayOfMonth     bool
 	daysOfMonthRestricted  bool
-	actualDaysOfMonthList  []int
 	monthList              []int
 	daysOfWeek             map[int]bool
 	specificWeekDaysOfWeek map[int]bool
@@ -45,6 +47,37 @@ type Expression struct {
 	daysOfWeekRestricted   bool
 	yearList               []int
 	timeZone               *time.Location
+	every                  time.Duration
+	anchor                 time.Time
+	reboot                 bool
+	systemd                bool
+	neverFires             bool
+	dialect                Dialect
+}
+
+// ErrNeverFires is returned by ParseStrict and ParseSystemdStrict for
+// well-formed expressions which do not match any time instant, such as
+// `0 0 30 2 *`.
+var ErrNeverFires = errors.New("expression never fires")
+
+// A SyntaxError is returned by Parse and ParseSystemd for malformed
+// expressions. It locates the offending token in the expression.
+type SyntaxError struct {
+	// Field is the name of the offending field, e.g. "minute", or empty if the
+	// error is not about a single field.
+	Field string
+	// Offset is the byte offset of Token in the expression, lowercased for
+	// systemd calendar events. If the error lies in the expansion of an
+	// alias, Token is the alias. For missing fields, it is the length of the
+	// expression.
+	Offset int
+	// Token is the offending token, empty if something is missing.
+	Token string
+	msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.msg
 }
 
 /******************************************************************************/
@@ -78,21 +111,35 @@ func MustParseSystemd(cronLine string) *Expression {
 // about what is a well-formed cron expression from this library's point of
 // view.
 func Parse(cronLine string) (*Expression, error) {
+	// Maybe one of the registered aliases is being used
+	cron, spans := cronAliases.expand(cronLine)
+	expr, err := parseCron(cron)
+	if err != nil {
+		return nil, unexpandSyntaxError(err, cronLine, spans)
+	}
+	return expr, nil
+}
 
-	// Maybe one of the built-in aliases is being used
-	cron := cronNormalizer.Replace(cronLine)
+func parseCron(cron string) (*Expression, error) {
+
+	// Maybe one of the special `@every` or `@reboot` aliases is being used
+	if expr, ok, err := parseSpecial(cron); ok {
+		return expr, err
+	}
 
 	indices := fieldFinder.FindAllStringIndex(cron, -1)
 	fieldCount := len(indices)
 	if fieldCount < 5 {
-		return nil, fmt.Errorf("missing field(s)")
+		return nil, &SyntaxError{Offset: len(cron), msg: "missing field(s)"}
 	}
 	// ignore fields beyond 7th
 	if fieldCount > 7 {
 		fieldCount = 7
 	}
 
-	var expr = Expression{}
+	var expr = Expression{
+		expression: cron,
+	}
 	var field = 0
 	var err error
 
@@ -100,7 +147,7 @@ func Parse(cronLine string) (*Expression, error) {
 	if fieldCount == 7 {
 		err = expr.secondFieldHandler(cron[indices[field][0]:indices[field][1]])
 		if err != nil {
-			return nil, err
+			return nil, shiftSyntaxError(err, indices[field][0])
 		}
 		field += 1
 	} else {
@@ -110,35 +157,35 @@ func Parse(cronLine string) (*Expression, error) {
 	// minute field
 	err = expr.minuteFieldHandler(cron[indices[field][0]:indices[field][1]])
 	if err != nil {
-		return nil, err
+		return nil, shiftSyntaxError(err, indices[field][0])
 	}
 	field += 1
 
 	// hour field
 	err = expr.hourFieldHandler(cron[indices[field][0]:indices[field][1]])
 	if err != nil {
-		return nil, err
+		return nil, shiftSyntaxError(err, indices[field][0])
 	}
 	field += 1
 
 	// day of month field
 	err = expr.domFieldHandler(cron[indices[field][0]:indices[field][1]])
 	if err != nil {
-		return nil, err
+		return nil, shiftSyntaxError(err, indices[field][0])
 	}
 	field += 1
 
 	// month field
 	err = expr.monthFieldHandler(cron[indices[field][0]:indices[field][1]])
 	if err != nil {
-		return nil, err
+		return nil, shiftSyntaxError(err, indices[field][0])
 	}
 	field += 1
 
 	// day of week field
 	err = expr.dowFieldHandler(cron[indices[field][0]:indices[field][1]])
 	if err != nil {
-		return nil, err
+		return nil, shiftSyntaxError(err, indices[field][0])
 	}
 	field += 1
 
@@ -146,18 +193,57 @@ func Parse(cronLine string) (*Expression, error) {
 	if field < fieldCount {
 		err = expr.yearFieldHandler(cron[indices[field][0]:indices[field][1]])
 		if err != nil {
-			return nil, err
+			return nil, shiftSyntaxError(err, indices[field][0])
 		}
 	} else {
 		expr.yearList = yearDescriptor.defaultList
 	}
 
+	expr.neverFires = !expr.isSatisfiable()
 	return &expr, nil
 }
 
+// ParseStrict is like Parse, but it also returns an error wrapping
+// ErrNeverFires if the expression does not match any time instant.
+func ParseStrict(cronLine string) (*Expression, error) {
+	return strict(Parse(cronLine))
+}
+
+// ParseSystemdStrict is like ParseSystemd, but it also returns an error
+// wrapping ErrNeverFires if the calendar event does not match any time
+// instant.
+func ParseSystemdStrict(systemdLine string) (*Expression, error) {
+	return strict(ParseSystemd(systemdLine))
+}
+
+func strict(expr *Expression, err error) (*Expression, error) {
+	if err != nil {
+		return nil, err
+	}
+	if expr.neverFires {
+		return nil, fmt.Errorf("'%s': %w", expr.expression, ErrNeverFires)
+	}
+	return expr, nil
+}
+
+// ParseSystemd returns a new Expression pointer from a systemd calendar event
+// as described in systemd.time(7). An error is returned if a malformed
+// calendar event is supplied.
 func ParseSystemd(systemdLine string) (*Expression, error) {
+	// Maybe one of the registered aliases is being used
+	systemdLine = strings.ToLower(systemdLine)
+	systemd, spans := systemdAliases.expand(systemdLine)
+	expr, err := parseSystemd(systemd)
+	if err != nil {
+		return nil, unexpandSyntaxError(err, systemdLine, spans)
+	}
+	return expr, nil
+}
+
+func parseSystemd(systemdLine string) (*Expression, error) {
 	var expr = Expression{
 		expression: systemdLine,
+		systemd:    true,
 	}
 	if err := expr.normalyzeSystemd(); err != nil {
 		return nil, fmt.Errorf("invalid expression, %s", err)
@@ -169,7 +255,7 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 	var err error
 
 	if fieldCount > 4 {
-		return nil, fmt.Errorf("too much field(s)")
+		return nil, &SyntaxError{Offset: indices[4][0], Token: expr.expression[indices[4][0]:indices[4][1]], msg: "too much field(s)"}
 	}
 
 	// Try parse weekday field
@@ -177,7 +263,7 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 		// parse weekday
 		err = expr.dowFieldHandler(expr.expression[indices[fieldI][0]:indices[fieldI][1]])
 		if err != nil {
-			return nil, err
+			return nil, shiftSyntaxError(err, indices[fieldI][0])
 		}
 		fieldI++
 	} else {
@@ -196,7 +282,7 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 		// day of month field
 		err = expr.domFieldHandler(dateString[DateIndices[len(DateIndices)-field][0]:DateIndices[len(DateIndices)-field][1]])
 		if err != nil {
-			return nil, err
+			return nil, shiftSyntaxError(err, indices[fieldI][0]+DateIndices[len(DateIndices)-field][0])
 		}
 		field += 1
 
@@ -204,7 +290,7 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 		if len(DateIndices)-field >= 0 {
 			err = expr.monthFieldHandler(dateString[DateIndices[len(DateIndices)-field][0]:DateIndices[len(DateIndices)-field][1]])
 			if err != nil {
-				return nil, err
+				return nil, shiftSyntaxError(err, indices[fieldI][0]+DateIndices[len(DateIndices)-field][0])
 			}
 			field += 1
 		} else {
@@ -214,12 +300,14 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 		// year field
 		if len(DateIndices)-field >= 0 {
 			yearString := dateString[DateIndices[len(DateIndices)-field][0]:DateIndices[len(DateIndices)-field][1]]
+			yearOffset := indices[fieldI][0] + DateIndices[len(DateIndices)-field][0]
 			if len(yearString) == 2 {
 				yearString = "20" + yearString
+				yearOffset -= 2
 			}
 			err = expr.yearFieldHandler(yearString)
 			if err != nil {
-				return nil, err
+				return nil, shiftSyntaxError(err, yearOffset)
 			}
 		} else {
 			expr.yearList = yearDescriptor.defaultList
@@ -241,14 +329,14 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 		// hour field
 		err = expr.hourFieldHandler(timeString[TimeIndices[field][0]:TimeIndices[field][1]])
 		if err != nil {
-			return nil, err
+			return nil, shiftSyntaxError(err, indices[fieldI][0]+TimeIndices[field][0])
 		}
 		field += 1
 
 		// minute field
 		err = expr.minuteFieldHandler(timeString[TimeIndices[field][0]:TimeIndices[field][1]])
 		if err != nil {
-			return nil, err
+			return nil, shiftSyntaxError(err, indices[fieldI][0]+TimeIndices[field][0])
 		}
 		field += 1
 
@@ -256,7 +344,7 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 		if field < len(TimeIndices) {
 			err = expr.secondFieldHandler(timeString[TimeIndices[field][0]:TimeIndices[field][1]])
 			if err != nil {
-				return nil, err
+				return nil, shiftSyntaxError(err, indices[fieldI][0]+TimeIndices[field][0])
 			}
 		} else {
 			err = expr.secondFieldHandler("00")
@@ -288,6 +376,7 @@ func ParseSystemd(systemdLine string) (*Expression, error) {
 			expr.timeZone = time.FixedZone(expr.expression[indices[fieldI][0]:indices[fieldI][1]], 0)
 		}
 	}
+	expr.neverFires = !expr.isSatisfiable()
 	return &expr, nil
 }
 
@@ -306,11 +395,20 @@ func (expr *Expression) Next(fromTime time.Time) time.Time {
 	if fromTime.IsZero() {
 		return fromTime
 	}
+	if expr.reboot || expr.neverFires {
+		return time.Time{}
+	}
+	if expr.every > 0 {
+		return expr.nextEvery(fromTime)
+	}
 	loc := fromTime.Location()
 	if expr.timeZone != nil {
 		loc = expr.timeZone
 	}
 	t := fromTime.Add(time.Second - time.Duration(fromTime.Nanosecond())*time.Nanosecond)
+	// Days of the month of t matched by expr, in a local so that concurrent
+	// calls on expr do not race
+	var days []int
 
 WRAP:
 
@@ -331,18 +429,18 @@ WRAP:
 		t = time.Date(t.Year(), time.Month(expr.monthList[i]), 1, 0, 0, 0, 0, loc)
 	}
 
-	expr.actualDaysOfMonthList = expr.calculateActualDaysOfMonth(t.Year(), int(t.Month()))
-	if len(expr.actualDaysOfMonthList) == 0 {
+	days = expr.calculateActualDaysOfMonth(t.Year(), int(t.Month()))
+	if len(days) == 0 {
 		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
 		goto WRAP
 	}
 
 	v = t.Day()
-	if i := sort.SearchInts(expr.actualDaysOfMonthList, v); i == len(expr.actualDaysOfMonthList) {
+	if i := sort.SearchInts(days, v); i == len(days) {
 		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
 		goto WRAP
-	} else if v != expr.actualDaysOfMonthList[i] {
-		t = time.Date(t.Year(), t.Month(), expr.actualDaysOfMonthList[i], 0, 0, 0, 0, loc)
+	} else if v != days[i] {
+		t = time.Date(t.Year(), t.Month(), days[i], 0, 0, 0, 0, loc)
 
 		// in San Palo, before 2019, there may be no midnight (or multiple midnights)
 		// due to DST
@@ -429,6 +527,151 @@ SLOW_CLOCK:
 
 /******************************************************************************/
 
+// Prev returns the closest time instant immediately preceding `fromTime` which
+// matches the cron expression `expr`.
+//
+// The `time.Location` of the returned time instant is the same as that of
+// `fromTime`.
+//
+// The zero value of time.Time is returned if no matching time instant exists
+// or if a `fromTime` is itself a zero value.
+func (expr *Expression) Prev(fromTime time.Time) time.Time {
+	// Special case
+	if fromTime.IsZero() || expr.reboot || expr.neverFires {
+		return time.Time{}
+	}
+	if expr.every > 0 {
+		return expr.prevEvery(fromTime)
+	}
+	loc := fromTime.Location()
+	if expr.timeZone != nil {
+		loc = expr.timeZone
+	}
+	earliest := time.Date(yearDescriptor.min, time.January, 1, 0, 0, 0, 0, loc)
+
+	// matchFrom returns the first time instant matching at or after the
+	// whole second `t`, and whether it precedes `fromTime`
+	matchFrom := func(t time.Time) (time.Time, bool) {
+		next := expr.Next(t.Add(-time.Nanosecond))
+		return next, !next.IsZero() && next.Before(fromTime)
+	}
+
+	// Widen the search backward until a match is found...
+	end := fromTime.Truncate(time.Second).Add(time.Second)
+	begin := end
+	for window := time.Second; ; window *= 2 {
+		begin = end.Add(-window)
+		if begin.Before(earliest) {
+			begin = earliest
+		}
+		if _, found := matchFrom(begin); found {
+			break
+		}
+		if !begin.After(earliest) {
+			return time.Time{}
+		}
+	}
+
+	// ...then narrow it down to the last second from which there is one
+	for end.Sub(begin) > time.Second {
+		middle := begin.Add(end.Sub(begin) / 2).Truncate(time.Second)
+		if _, found := matchFrom(middle); found {
+			begin = middle
+		} else {
+			end = middle
+		}
+	}
+	prev, _ := matchFrom(begin)
+	return prev
+}
+
+/******************************************************************************/
+
+// IsSatisfiable reports whether `expr` matches at least one time instant
+// between 1970 and 2099, regardless of the current time. It is decided once
+// when the expression is parsed: Next returns a zero value at once for
+// expressions which are not satisfiable.
+func (expr *Expression) IsSatisfiable() bool {
+	return !expr.neverFires
+}
+
+func (expr *Expression) isSatisfiable() bool {
+	if len(expr.hourList) == 0 || len(expr.minuteList) == 0 || len(expr.secondList) == 0 {
+		return false
+	}
+	days := newDayMatcher(expr)
+	for _, y := range expr.yearList {
+		for _, m := range expr.monthList {
+			if len(days.days(y, m)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/******************************************************************************/
+
+// Every returns the interval of an `@every <duration>` expression, or zero if
+// `expr` is not such an expression.
+func (expr *Expression) Every() time.Duration {
+	return expr.every
+}
+
+// Anchor returns the time instant from which the intervals of an
+// `@every <duration>` expression are counted. Unless changed with WithAnchor,
+// this is the Unix epoch.
+func (expr *Expression) Anchor() time.Time {
+	if expr.anchor.IsZero() {
+		return time.Unix(0, 0).UTC()
+	}
+	return expr.anchor
+}
+
+// WithAnchor returns a copy of an `@every <duration>` expression whose
+// intervals are counted from `anchor` instead of the Unix epoch, typically the
+// time at which a job was started.
+func (expr *Expression) WithAnchor(anchor time.Time) *Expression {
+	anchored := *expr
+	anchored.anchor = anchor
+	return &anchored
+}
+
+// IsReboot reports whether `expr` is the `@reboot` expression, which stands
+// for a single run at startup rather than for a point in time. Next always
+// returns the zero value of time.Time for such an expression.
+func (expr *Expression) IsReboot() bool {
+	return expr.reboot
+}
+
+func (expr *Expression) nextEvery(fromTime time.Time) time.Time {
+	loc := fromTime.Location()
+	if expr.timeZone != nil {
+		loc = expr.timeZone
+	}
+	anchor := expr.Anchor()
+	if fromTime.Before(anchor) {
+		return anchor.In(loc)
+	}
+	n := fromTime.Sub(anchor)/expr.every + 1
+	return anchor.Add(n * expr.every).In(loc)
+}
+
+func (expr *Expression) prevEvery(fromTime time.Time) time.Time {
+	loc := fromTime.Location()
+	if expr.timeZone != nil {
+		loc = expr.timeZone
+	}
+	anchor := expr.Anchor()
+	if !fromTime.After(anchor) {
+		return time.Time{}
+	}
+	n := (fromTime.Sub(anchor) - 1) / expr.every
+	return anchor.Add(n * expr.every).In(loc)
+}
+
+/******************************************************************************/
+
 // NextN returns a slice of `n` closest time instants immediately following
 // `fromTime` which match the cron expression `expr`.
 //
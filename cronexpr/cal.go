@@ -0,0 +1,134 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/cal.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// cellWidth is the width of a day in the calendar grid: the day, a space and
+// the count of firings in parentheses.
+const cellWidth = 10
+
+// runCal prints the calendar of a month, as `cal` does, with the days on
+// which an expression fires highlighted and followed by their number of
+// firings.
+func runCal(args []string) int {
+	var f exprFlags
+	var plain bool
+	fs := newFlagSet("cal", `[options] "{expression}" [YYYY-MM]`)
+	f.register(fs)
+	fs.BoolVar(&plain, "plain", false, `do not highlight days, which is otherwise done when the output is a terminal`)
+	if code, ok := parseFlags(fs, args, 1, 2); !ok {
+		return code
+	}
+	inTime, err := f.parseTime()
+	if err != nil {
+		return failUsage(err)
+	}
+	if fs.NArg() == 2 {
+		inTime, err = time.ParseInLocation("2006-01", fs.Arg(1), inTime.Location())
+		if err != nil {
+			return failUsage(fmt.Errorf("unparseable month: \"%s\"", fs.Arg(1)))
+		}
+	}
+	expr, err := f.parseExpr(fs.Arg(0))
+	if err != nil {
+		return failInvalid(err)
+	}
+
+	first := time.Date(inTime.Year(), inTime.Month(), 1, 0, 0, 0, 0, inTime.Location())
+	counts := expr.CountPer(cronexpr.PerDay, first, first.AddDate(0, 1, 0))
+	highlight := !plain && isTerminal(os.Stdout)
+	printMonth(first, expr.MatchingDays(first.Year(), first.Month()), counts, highlight)
+
+	total, days := 0, 0
+	for _, count := range counts {
+		total += count.Count
+		if count.Count > 0 {
+			days += 1
+		}
+	}
+	fmt.Printf("\n%s: %d firing(s) on %d day(s)\n", fs.Arg(0), total, days)
+	return exitOK
+}
+
+// printMonth prints the calendar grid of the month starting at `first`, with
+// `days` highlighted, and the non-zero `counts` of each day.
+func printMonth(first time.Time, days []int, counts []cronexpr.PeriodCount, highlight bool) {
+	matching := make(map[int]bool, len(days))
+	for _, day := range days {
+		matching[day] = true
+	}
+	// Days fired on without being bound to, such as with `@every`
+	for _, count := range counts {
+		if count.Count > 0 {
+			matching[count.Start.Day()] = true
+		}
+	}
+
+	width := 7*cellWidth - 1
+	title := first.Format("January 2006")
+	fmt.Printf("%*s\n", (width+len(title))/2, title)
+	var header []string
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		header = append(header, fmt.Sprintf("%-*s", cellWidth-1, wd.String()[:2]))
+	}
+	fmt.Println(strings.TrimRight(strings.Join(header, " "), " "))
+
+	var row []string
+	for i := 0; i < int(first.Weekday()); i++ {
+		row = append(row, strings.Repeat(" ", cellWidth-1))
+	}
+	for _, count := range counts {
+		day := count.Start.Day()
+		cell := fmt.Sprintf("%2d", day)
+		if matching[day] {
+			if highlight {
+				// Reverse video
+				cell = "\x1b[7m" + cell + "\x1b[0m"
+			}
+			cell += fmt.Sprintf(" %-*s", cellWidth-4, "("+formatCount(count.Count)+")")
+		} else {
+			cell += strings.Repeat(" ", cellWidth-3)
+		}
+		row = append(row, cell)
+		if len(row) == 7 {
+			fmt.Println(strings.TrimRight(strings.Join(row, " "), " "))
+			row = row[:0]
+		}
+	}
+	if len(row) > 0 {
+		fmt.Println(strings.TrimRight(strings.Join(row, " "), " "))
+	}
+}
+
+// formatCount formats a number of firings within a day on four characters at
+// most, e.g. "1440" or "86k".
+func formatCount(n int) string {
+	if n >= 10000 {
+		return fmt.Sprintf("%dk", n/1000)
+	}
+	return fmt.Sprint(n)
+}
+
+// isTerminal tells whether `f` is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
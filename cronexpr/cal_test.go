@@ -0,0 +1,50 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/cal_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/******************************************************************************/
+
+func TestCal(t *testing.T) {
+	stdout, _, code := runCommand(t, "", "cal", "-tz", "UTC", "0 9,17 * * mon-fri", "2013-09")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "                           September 2013\n"+
+		"Su        Mo        Tu        We        Th        Fr        Sa\n"+
+		" 1         2 (2)     3 (2)     4 (2)     5 (2)     6 (2)     7\n"+
+		" 8         9 (2)    10 (2)    11 (2)    12 (2)    13 (2)    14\n"+
+		"15        16 (2)    17 (2)    18 (2)    19 (2)    20 (2)    21\n"+
+		"22        23 (2)    24 (2)    25 (2)    26 (2)    27 (2)    28\n"+
+		"29        30 (2)\n"+
+		"\n"+
+		"0 9,17 * * mon-fri: 42 firing(s) on 21 day(s)\n", stdout)
+
+	// Days fired on by `@every` are highlighted too
+	stdout, _, code = runCommand(t, "", "cal", "-tz", "UTC", "@every 6h", "2013-02")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, " 3 (4)")
+	assert.Contains(t, stdout, "@every 6h: 112 firing(s) on 28 day(s)\n")
+
+	_, _, code = runCommand(t, "", "cal", "-tz", "UTC", "0 0 * * *", "2013-13")
+	assert.Equal(t, exitUsage, code)
+	_, _, code = runCommand(t, "", "cal", "-tz", "UTC", "0 0 32 * *", "2013-09")
+	assert.Equal(t, exitInvalid, code)
+}
+
+func TestFormatCount(t *testing.T) {
+	assert.Equal(t, "1", formatCount(1))
+	assert.Equal(t, "1440", formatCount(1440))
+	assert.Equal(t, "86k", formatCount(86400))
+}
@@ -0,0 +1,61 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/convert.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// runConvert writes an expression in another dialect, or lists the
+// constructs which the other dialect cannot express.
+func runConvert(args []string) int {
+	var from, to string
+	fs := newFlagSet("convert", `[options] "{expression}"`)
+	fs.StringVar(&from, "from", "cron", `dialect of the expression: "cron", "quartz", "systemd" or "eventbridge"`)
+	fs.StringVar(&to, "to", "", `dialect to convert the expression to: "cron", "quartz", "systemd" or "eventbridge"`)
+	if code, ok := parseFlags(fs, args, 1, 1); !ok {
+		return code
+	}
+	fromDialect, err := cronexpr.LookupDialect(from)
+	if err != nil {
+		return failUsage(err)
+	}
+	if to == "" {
+		return failUsage(errors.New("missing -to option"))
+	}
+	toDialect, err := cronexpr.LookupDialect(to)
+	if err != nil {
+		return failUsage(err)
+	}
+	expr, err := cronexpr.ParseDialect(fs.Arg(0), fromDialect)
+	if err != nil {
+		return failInvalid(err)
+	}
+
+	converted, err := cronexpr.Convert(expr, toDialect)
+	var cerr *cronexpr.ConvertError
+	if errors.As(err, &cerr) {
+		for _, u := range cerr.Unsupported {
+			fmt.Fprintf(os.Stderr, "# %s cannot express %s\n", toDialect, u)
+		}
+		return exitInvalid
+	} else if err != nil {
+		return failInvalid(err)
+	}
+	fmt.Println(converted)
+	return exitOK
+}
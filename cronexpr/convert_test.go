@@ -0,0 +1,47 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/convert_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/******************************************************************************/
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"-to", "quartz", "*/15 9-17 * * mon-fri"}, exitOK, "0 0/15 9-17 ? * 2-6\n", ""},
+		{[]string{"-from", "quartz", "-to", "cron", "0 0 12 ? * L"}, exitOK, "0 12 * * 6\n", ""},
+		{[]string{"-from", "eventbridge", "-to", "systemd", "cron(0 18 ? * MON-FRI *)"}, exitOK, "Mon..Fri *-*-* 18:00:00\n", ""},
+		{[]string{"-from", "quartz", "-to", "cron", "0 0 12 ? * 6#3"}, exitInvalid, "", "# cron cannot express '6#3' in day-of-week field (nth day of the week of the month)\n"},
+		{[]string{"-to", "cron", "0 0 30 2 *"}, exitInvalid, "", "# cron cannot express '0 0 30 2 *' (never fires)\n"},
+		{[]string{"0 0 * * *"}, exitUsage, "", "# error: missing -to option\n"},
+		{[]string{"-from", "fcron", "-to", "cron", "0 0 * * *"}, exitUsage, "", "# error: unknown dialect: \"fcron\"\n"},
+	}
+	for _, c := range cases {
+		stdout, stderr, code := runCommand(t, "", append([]string{"convert"}, c.args...)...)
+		assert.Equalf(t, c.code, code, "%q", c.args)
+		assert.Equalf(t, c.stdout, stdout, "%q", c.args)
+		assert.Equalf(t, c.stderr, stderr, "%q", c.args)
+	}
+
+	// Syntax errors quote the token as written
+	_, stderr, code := runCommand(t, "", "convert", "-from", "quartz", "-to", "cron", "0 0 12 ? * 6#9")
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stderr, "syntax error in day-of-week field: '6#9'\n")
+}
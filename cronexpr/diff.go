@@ -0,0 +1,151 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/diff.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// A diffListing is the result of `diff`, as output in the JSON and CSV
+// formats.
+type diffListing struct {
+	Old      string `json:"old"`
+	New      string `json:"new"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	TimeZone string `json:"timezone"`
+	// OnlyOld and OnlyNew are limited by the -n option, unlike their counts.
+	OnlyOld      []listingTime `json:"only_old"`
+	OnlyNew      []listingTime `json:"only_new"`
+	OnlyOldCount int           `json:"only_old_count"`
+	OnlyNewCount int           `json:"only_new_count"`
+	Common       int           `json:"common"`
+}
+
+// writeJSON outputs `l` in JSON.
+func (l *diffListing) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+/******************************************************************************/
+
+// runDiff lists the time values matched by only one of two expressions within
+// a range. Like diff(1), it exits with a distinct code, exitDiffer, if they
+// differ. The text and CSV formats are output as the time values are found,
+// only the JSON one is buffered.
+func runDiff(args []string) int {
+	var f listFlags
+	var from, to string
+	fs := newFlagSet("diff", `[options] "{old expression}" "{new expression}"`)
+	usage := fs.Usage
+	fs.Usage = func() {
+		usage()
+		fmt.Fprintf(fs.Output(), "The exit code is %d if the expressions differ.\n", exitDiffer)
+	}
+	fs.StringVar(&from, "from", "", `whole or partial RFC3339 time value from which the expressions are compared, now if not present`)
+	fs.StringVar(&to, "to", "", `whole or partial RFC3339 time value up to which the expressions are compared, a week after -from if not present`)
+	fs.StringVar(&f.tz, "tz", "", `IANA time zone (i.e. "America/New_York") in which the expressions are evaluated and time values are output, the local time zone if not present`)
+	fs.BoolVar(&f.systemd, "systemd", false, `compare systemd calendar events (i.e. "Mon..Fri *-*-* 09:00") instead of cron expressions`)
+	fs.UintVar(&f.count, "n", 0, `maximum number of differing time values to output per expression, no limit if 0`)
+	f.registerLayout(fs)
+	fs.StringVar(&f.output, "o", outputText, `output format: "text", or "json" or "csv" for scripts, with time values in RFC3339 and as Unix timestamps`)
+	if code, ok := f.parse(fs, args, 2, 2); !ok {
+		return code
+	}
+	f.time = from
+	fromTime, err := f.parseTime()
+	if err != nil {
+		return failUsage(err)
+	}
+	toTime := fromTime.AddDate(0, 0, 7)
+	if to != "" {
+		loc, _ := f.location()
+		if toTime, err = parseTime(to, loc); err != nil {
+			return failUsage(err)
+		}
+	}
+	oldExpr, err := f.parseExpr(fs.Arg(0))
+	if err != nil {
+		return failInvalid(err)
+	}
+	newExpr, err := f.parseExpr(fs.Arg(1))
+	if err != nil {
+		return failInvalid(err)
+	}
+
+	l := &diffListing{
+		Old:      fs.Arg(0),
+		New:      fs.Arg(1),
+		From:     fromTime.Format(time.RFC3339),
+		To:       toTime.Format(time.RFC3339),
+		TimeZone: zoneName(fromTime),
+		OnlyOld:  []listingTime{},
+		OnlyNew:  []listingTime{},
+	}
+	var cw *csv.Writer
+	switch f.output {
+	case outputText:
+		fmt.Printf("# \"%s\" -> \"%s\" in [\"%s\", \"%s\") =\n", l.Old, l.New, l.From, l.To)
+	case outputCSV:
+		cw = csv.NewWriter(os.Stdout)
+		cw.Write([]string{"side", "time", "unix", "offset"})
+	}
+	l.Common = cronexpr.Diff(oldExpr, newExpr, fromTime, toTime, func(t time.Time, side cronexpr.DiffSide) bool {
+		times, count, name, sign := &l.OnlyOld, &l.OnlyOldCount, "old", "-"
+		if side == cronexpr.OnlyNew {
+			times, count, name, sign = &l.OnlyNew, &l.OnlyNewCount, "new", "+"
+		}
+		*count += 1
+		if f.count != 0 && uint(*count) > f.count {
+			return true
+		}
+		switch f.output {
+		case outputText:
+			fmt.Println(sign + " " + t.Format(f.textLayout()))
+		case outputCSV:
+			lt := newListingTime(t)
+			cw.Write([]string{name, lt.Time, strconv.FormatInt(lt.Unix, 10), lt.Offset})
+		default:
+			*times = append(*times, newListingTime(t))
+		}
+		return true
+	})
+
+	switch f.output {
+	case outputText:
+		fmt.Printf("# %d only in old, %d only in new, %d in common\n", l.OnlyOldCount, l.OnlyNewCount, l.Common)
+	case outputCSV:
+		cw.Flush()
+		err = cw.Error()
+	default:
+		err = l.writeJSON(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "# error: %s\n", err)
+		return exitInvalid
+	}
+	if l.OnlyOldCount > 0 || l.OnlyNewCount > 0 {
+		return exitDiffer
+	}
+	return exitOK
+}
@@ -0,0 +1,69 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/diff_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/******************************************************************************/
+
+func TestDiff(t *testing.T) {
+	args := []string{"diff", "-tz", "UTC", "-from", "2013-09-01", "-to", "2013-09-08"}
+
+	stdout, _, code := runCommand(t, "", append(args, "0 12 * * mon-fri", "0 12 * * 1,3,5,6")...)
+	assert.Equal(t, exitDiffer, code)
+	assert.Equal(t, "# \"0 12 * * mon-fri\" -> \"0 12 * * 1,3,5,6\" in [\"2013-09-01T00:00:00Z\", \"2013-09-08T00:00:00Z\") =\n"+
+		"- Tue, 03 Sep 2013 12:00:00 UTC +00:00\n"+
+		"- Thu, 05 Sep 2013 12:00:00 UTC +00:00\n"+
+		"+ Sat, 07 Sep 2013 12:00:00 UTC +00:00\n"+
+		"# 2 only in old, 1 only in new, 3 in common\n", stdout)
+
+	stdout, _, code = runCommand(t, "", append(args, "0 12 * * *", "0 12 * * *")...)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "# \"0 12 * * *\" -> \"0 12 * * *\" in [\"2013-09-01T00:00:00Z\", \"2013-09-08T00:00:00Z\") =\n"+
+		"# 0 only in old, 0 only in new, 7 in common\n", stdout)
+
+	// -n limits the listed time values, not their counts
+	stdout, _, code = runCommand(t, "", append(args, "-n", "1", "-o", "json", "0 12 * * *", "@daily")...)
+	assert.Equal(t, exitDiffer, code)
+	var l diffListing
+	require.NoError(t, json.Unmarshal([]byte(stdout), &l))
+	assert.Equal(t, []listingTime{{Time: "2013-09-01T12:00:00Z", Unix: 1378036800, Offset: "+00:00"}}, l.OnlyOld)
+	assert.Equal(t, []listingTime{{Time: "2013-09-01T00:00:00Z", Unix: 1377993600, Offset: "+00:00"}}, l.OnlyNew)
+	assert.Equal(t, 7, l.OnlyOldCount)
+	assert.Equal(t, 7, l.OnlyNewCount)
+	assert.Equal(t, 0, l.Common)
+
+	stdout, _, _ = runCommand(t, "", append(args, "-o", "csv", "0 12 * * mon-fri", "0 12 * * 1,3,5,6")...)
+	assert.Equal(t, "side,time,unix,offset\n"+
+		"old,2013-09-03T12:00:00Z,1378209600,+00:00\n"+
+		"old,2013-09-05T12:00:00Z,1378382400,+00:00\n"+
+		"new,2013-09-07T12:00:00Z,1378555200,+00:00\n", stdout)
+
+	// The text and CSV formats list the differing time values in
+	// chronological order, as they are found
+	stdout, _, _ = runCommand(t, "", append(args, "-n", "2", "-o", "csv", "0 12 * * *", "0 0 * * *")...)
+	assert.Equal(t, "side,time,unix,offset\n"+
+		"new,2013-09-01T00:00:00Z,1377993600,+00:00\n"+
+		"old,2013-09-01T12:00:00Z,1378036800,+00:00\n"+
+		"new,2013-09-02T00:00:00Z,1378080000,+00:00\n"+
+		"old,2013-09-02T12:00:00Z,1378123200,+00:00\n", stdout)
+
+	_, _, code = runCommand(t, "", append(args, "0 12 * * *", "0 25 * * *")...)
+	assert.Equal(t, exitInvalid, code)
+	_, _, code = runCommand(t, "", "diff", "0 12 * * *")
+	assert.Equal(t, exitUsage, code)
+}
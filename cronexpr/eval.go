@@ -0,0 +1,269 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/eval.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// listFlags are the options of the commands listing time values.
+type listFlags struct {
+	exprFlags
+	count  uint
+	layout string
+	offset bool
+	output string
+}
+
+func (f *listFlags) register(fs *flag.FlagSet, count uint, countUsage string) {
+	f.exprFlags.register(fs)
+	fs.UintVar(&f.count, "n", count, countUsage)
+	f.registerLayout(fs)
+	fs.StringVar(&f.output, "o", outputText, `output format: "text", or "json" or "csv" for scripts, with time values in RFC3339 and as Unix timestamps`)
+}
+
+// registerLayout registers the options of the layout of time values in the
+// text output.
+func (f *listFlags) registerLayout(fs *flag.FlagSet) {
+	fs.StringVar(&f.layout, "l", defaultLayout, `Go-compliant time layout to use for outputting time value(s), see <http://golang.org/pkg/time/#pkg-constants>`)
+	fs.BoolVar(&f.offset, "offset", true, `append the UTC offset of each time value to the layout (i.e. "-05:00"), so that daylight saving changes are visible, -offset=false to leave it out`)
+}
+
+// textLayout returns the layout of time values in the text output.
+func (f *listFlags) textLayout() string {
+	if f.offset {
+		return f.layout + offsetLayout
+	}
+	return f.layout
+}
+
+// parse parses `args` with `fs` as parseFlags does, and checks the options.
+func (f *listFlags) parse(fs *flag.FlagSet, args []string, min, max int) (int, bool) {
+	code, ok := parseFlags(fs, args, min, max)
+	if !ok {
+		return code, false
+	}
+	if err := checkFormat(f.output); err != nil {
+		return failUsage(err), false
+	}
+	return exitOK, true
+}
+
+// print outputs `l` in the format of the -o option, using `header` as the
+// header of the text format.
+func (f *listFlags) print(l *listing, times []time.Time, header string) int {
+	if f.output != outputText {
+		if err := l.write(os.Stdout, f.output); err != nil {
+			fmt.Fprintf(os.Stderr, "# error: %s\n", err)
+			return exitInvalid
+		}
+		return exitOK
+	}
+	// Anything on the output which starts with '#' can be ignored if the caller
+	// is interested only in the time values. There is only one time
+	// value per line, and they are always in chronological ascending order.
+	fmt.Println(header)
+	for _, t := range times {
+		fmt.Println(t.Format(f.textLayout()))
+	}
+	return exitOK
+}
+
+/******************************************************************************/
+
+func runNext(args []string) int {
+	var f listFlags
+	fs := newFlagSet("next", `[options] "{expression}"`)
+	f.register(fs, 1, `number of resulting time values to output`)
+	if code, ok := f.parse(fs, args, 1, 1); !ok {
+		return code
+	}
+	inTime, err := f.parseTime()
+	if err != nil {
+		return failUsage(err)
+	}
+	expr, err := f.parseExpr(fs.Arg(0))
+	if err != nil {
+		return failInvalid(err)
+	}
+
+	if f.count < 1 {
+		f.count = 1
+	}
+	outTimes := expr.NextN(inTime, f.count)
+	if f.systemd && f.output == outputText {
+		printSystemd(fs.Arg(0), expr, outTimes)
+		return exitOK
+	}
+	l := newListing(fs.Arg(0), expr.Normalized(), inTime, outTimes)
+	return f.print(l, outTimes, fmt.Sprintf("# \"%s\" + \"%s\" =", fs.Arg(0), inTime.Format(time.RFC3339)))
+}
+
+func runPrev(args []string) int {
+	var f listFlags
+	fs := newFlagSet("prev", `[options] "{expression}"`)
+	f.register(fs, 1, `number of resulting time values to output`)
+	if code, ok := f.parse(fs, args, 1, 1); !ok {
+		return code
+	}
+	inTime, err := f.parseTime()
+	if err != nil {
+		return failUsage(err)
+	}
+	expr, err := f.parseExpr(fs.Arg(0))
+	if err != nil {
+		return failInvalid(err)
+	}
+
+	if f.count < 1 {
+		f.count = 1
+	}
+	var outTimes []time.Time
+	for t := expr.Prev(inTime); !t.IsZero() && uint(len(outTimes)) < f.count; t = expr.Prev(t) {
+		outTimes = append(outTimes, t)
+	}
+	// Chronological ascending order, as for other commands
+	for i, j := 0, len(outTimes)-1; i < j; i, j = i+1, j-1 {
+		outTimes[i], outTimes[j] = outTimes[j], outTimes[i]
+	}
+	l := newListing(fs.Arg(0), expr.Normalized(), inTime, outTimes)
+	return f.print(l, outTimes, fmt.Sprintf("# \"%s\" - \"%s\" =", fs.Arg(0), inTime.Format(time.RFC3339)))
+}
+
+func runBetween(args []string) int {
+	var f listFlags
+	fs := newFlagSet("between", `[options] "{expression}" FROM TO`)
+	f.register(fs, 0, `maximum number of resulting time values to output, no limit if 0`)
+	if code, ok := f.parse(fs, args, 3, 3); !ok {
+		return code
+	}
+	loc, err := f.location()
+	if err != nil {
+		return failUsage(err)
+	}
+	from, err := parseTime(fs.Arg(1), loc)
+	if err != nil {
+		return failUsage(err)
+	}
+	to, err := parseTime(fs.Arg(2), loc)
+	if err != nil {
+		return failUsage(err)
+	}
+	expr, err := f.parseExpr(fs.Arg(0))
+	if err != nil {
+		return failInvalid(err)
+	}
+
+	outTimes := between(expr, from, to, f.count)
+	l := newListing(fs.Arg(0), expr.Normalized(), from, outTimes)
+	l.Until = to.Format(time.RFC3339)
+	return f.print(l, outTimes, fmt.Sprintf("# \"%s\" in [\"%s\", \"%s\") =", fs.Arg(0), from.Format(time.RFC3339), to.Format(time.RFC3339)))
+}
+
+// between returns the time values matched by `expr` from `from` up to `to`
+// excluded, at most `limit` of them unless it is zero.
+func between(expr *cronexpr.Expression, from, to time.Time, limit uint) []time.Time {
+	var times []time.Time
+	for t := expr.Next(from.Add(-time.Second)); !t.IsZero() && t.Before(to); t = expr.Next(t) {
+		if t.Before(from) {
+			continue
+		}
+		times = append(times, t)
+		if uint(len(times)) == limit {
+			break
+		}
+	}
+	return times
+}
+
+/******************************************************************************/
+
+// printSystemd outputs the time values matched by `expr` the way
+// `systemd-analyze calendar --iterations` does.
+func printSystemd(systemdStr string, expr *cronexpr.Expression, outTimes []time.Time) {
+	now := time.Now()
+	fmt.Printf("  Original form: %s\n", systemdStr)
+	fmt.Printf("Normalized form: %s\n", expr.Normalized())
+
+	if len(outTimes) == 0 {
+		fmt.Printf("    Next elapse: never\n")
+		return
+	}
+	for i, outTime := range outTimes {
+		label := "Next elapse"
+		if i > 0 {
+			label = fmt.Sprintf("Iteration #%d", i+1)
+		}
+		fmt.Printf("%15s: %s\n", label, outTime.Format(systemdLayout))
+		if name, offset := outTime.Zone(); name != "UTC" || offset != 0 {
+			fmt.Printf("       (in UTC): %s\n", outTime.UTC().Format(systemdLayout))
+		}
+		fmt.Printf("       From now: %s\n", formatRelative(outTime.Sub(now)))
+	}
+}
+
+// formatRelative formats `d` the way systemd formats relative timestamps,
+// e.g. "1 month 4 days left" or "3h 20min ago".
+func formatRelative(d time.Duration) string {
+	suffix := "left"
+	if d < 0 {
+		d, suffix = -d, "ago"
+	}
+	const (
+		day   = 24 * time.Hour
+		week  = 7 * day
+		month = 2629800 * time.Second
+		year  = 31557600 * time.Second
+	)
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	var s string
+	switch {
+	case d >= year:
+		s = plural(int64(d/year), "year") + " " + plural(int64(d%year/month), "month")
+	case d >= month:
+		s = plural(int64(d/month), "month") + " " + plural(int64(d%month/day), "day")
+	case d >= week:
+		s = plural(int64(d/week), "week") + " " + plural(int64(d%week/day), "day")
+	case d >= 2*day:
+		s = plural(int64(d/day), "day")
+	case d >= 25*time.Hour:
+		s = fmt.Sprintf("1 day %dh", (d-day)/time.Hour)
+	case d >= 6*time.Hour:
+		s = fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Hour:
+		s = fmt.Sprintf("%dh %dmin", d/time.Hour, d%time.Hour/time.Minute)
+	case d >= 5*time.Minute:
+		s = fmt.Sprintf("%dmin", d/time.Minute)
+	case d >= time.Minute:
+		s = fmt.Sprintf("%dmin %ds", d/time.Minute, d%time.Minute/time.Second)
+	case d >= time.Second:
+		s = fmt.Sprintf("%ds", d/time.Second)
+	case d >= time.Millisecond:
+		s = fmt.Sprintf("%dms", d/time.Millisecond)
+	case d > 0:
+		s = fmt.Sprintf("%dus", d/time.Microsecond)
+	default:
+		return "now"
+	}
+	return s + " " + suffix
+}
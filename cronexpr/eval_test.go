@@ -0,0 +1,146 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/eval_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/******************************************************************************/
+
+func TestSystemd(t *testing.T) {
+	stdout, _, code := runCommand(t, "", "next", "-systemd", "-tz", "UTC", "-t", "2013-09-01T00:00:00Z", "-n", "2", "mon..fri 9:00")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "  Original form: mon..fri 9:00\nNormalized form: Mon..Fri *-*-* 09:00:00\n")
+	assert.Contains(t, stdout, "    Next elapse: Mon 2013-09-02 09:00:00 UTC\n")
+	assert.Contains(t, stdout, "   Iteration #2: Tue 2013-09-03 09:00:00 UTC\n")
+	assert.NotContains(t, stdout, "(in UTC)")
+
+	stdout, _, code = runCommand(t, "", "next", "-systemd", "-tz", "UTC", "-t", "2013-09-01T00:00:00Z", "*-02-30")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "    Next elapse: never\n")
+}
+
+func TestFormatRelative(t *testing.T) {
+	cases := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "now"},
+		{90 * time.Second, "1min 30s left"},
+		{-(3*time.Hour + 20*time.Minute), "3h 20min ago"},
+		{26 * time.Hour, "1 day 2h left"},
+		{10 * 24 * time.Hour, "1 week 3 days left"},
+		{400 * 24 * time.Hour, "1 year 1 month left"},
+	}
+	for _, c := range cases {
+		assert.Equalf(t, c.expected, formatRelative(c.d), "formatRelative(%s)", c.d)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no arguments", nil, exitUsage},
+		{"help", []string{"help"}, exitOK},
+		{"command help", []string{"next", "-h"}, exitOK},
+		{"valid", []string{"next", "0 0 * * *"}, exitOK},
+		{"legacy mode", []string{"0 0 * * *"}, exitOK},
+		{"malformed expression", []string{"next", "0 25 * * *"}, exitInvalid},
+		{"missing expression", []string{"next"}, exitUsage},
+		{"too many arguments", []string{"next", "0 0 * * *", "0 1 * * *"}, exitUsage},
+		{"unknown option", []string{"next", "-bogus", "0 0 * * *"}, exitUsage},
+		{"unknown time zone", []string{"next", "-tz", "Mars/Base", "0 0 * * *"}, exitUsage},
+		{"unparseable time", []string{"next", "-t", "yesterday", "0 0 * * *"}, exitUsage},
+		{"unknown output format", []string{"next", "-o", "xml", "0 0 * * *"}, exitUsage},
+		{"unparseable range", []string{"between", "0 0 * * *", "2013-09-01", "later"}, exitUsage},
+		{"valid expressions", []string{"validate", "0 0 * * *", "@daily"}, exitOK},
+		{"expression which never fires", []string{"validate", "0 0 * * *", "0 0 30 2 *"}, exitInvalid},
+	}
+	for _, c := range cases {
+		_, _, code := runCommand(t, "", c.args...)
+		assert.Equalf(t, c.code, code, "%s: %q", c.name, c.args)
+	}
+}
+
+func TestListCommands(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			[]string{"next", "-tz", "UTC", "-t", "2013-09-01T00:00:00Z", "-n", "3", "0 0 31 12 *"},
+			"# \"0 0 31 12 *\" + \"2013-09-01T00:00:00Z\" =\n" +
+				"Tue, 31 Dec 2013 00:00:00 UTC +00:00\n" +
+				"Wed, 31 Dec 2014 00:00:00 UTC +00:00\n" +
+				"Thu, 31 Dec 2015 00:00:00 UTC +00:00\n",
+		},
+		{
+			[]string{"-tz", "UTC", "-t", "2013-09-01", "0 12 * * *"},
+			"# \"0 12 * * *\" + \"2013-09-01T00:00:00Z\" =\n" +
+				"Sun, 01 Sep 2013 12:00:00 UTC +00:00\n",
+		},
+		{
+			[]string{"prev", "-tz", "UTC", "-t", "2013-09-01T00:00:00Z", "-n", "2", "0 12 * * *"},
+			"# \"0 12 * * *\" - \"2013-09-01T00:00:00Z\" =\n" +
+				"Fri, 30 Aug 2013 12:00:00 UTC +00:00\n" +
+				"Sat, 31 Aug 2013 12:00:00 UTC +00:00\n",
+		},
+		{
+			[]string{"between", "-tz", "UTC", "0 0 * * mon", "2013-09-01", "2013-09-16"},
+			"# \"0 0 * * mon\" in [\"2013-09-01T00:00:00Z\", \"2013-09-16T00:00:00Z\") =\n" +
+				"Mon, 02 Sep 2013 00:00:00 UTC +00:00\n" +
+				"Mon, 09 Sep 2013 00:00:00 UTC +00:00\n",
+		},
+		{
+			[]string{"between", "-tz", "UTC", "-n", "1", "0 0 * * mon", "2013-09-01", "2013-09-16"},
+			"# \"0 0 * * mon\" in [\"2013-09-01T00:00:00Z\", \"2013-09-16T00:00:00Z\") =\n" +
+				"Mon, 02 Sep 2013 00:00:00 UTC +00:00\n",
+		},
+		{
+			[]string{"next", "-tz", "UTC", "-t", "2013-09-01T00:00:00Z", "-l", "2006-01-02 15:04", "0 12 * * *"},
+			"# \"0 12 * * *\" + \"2013-09-01T00:00:00Z\" =\n" +
+				"2013-09-01 12:00 +00:00\n",
+		},
+		{
+			[]string{"explain", "-tz", "UTC", "-t", "2013-09-01", "@daily"},
+			"expression:  @daily\n" +
+				"normalized:  0 0 * * *\n" +
+				"description: at 00:00, every day\n" +
+				"next:        2013-09-02T00:00:00Z\n",
+		},
+		{
+			[]string{"explain", "-tz", "UTC", "-t", "2013-09-01", "0 0 30 2 *"},
+			"expression:  0 0 30 2 *\n" +
+				"normalized:  0 0 30 2 *\n" +
+				"description: never\n" +
+				"next:        never\n" +
+				"warning:     expression never fires\n",
+		},
+		{
+			[]string{"validate", "0 0 * * *", "0 25 * * *"},
+			"\"0 0 * * *\": valid\n",
+		},
+	}
+	for _, c := range cases {
+		stdout, _, _ := runCommand(t, "", c.args...)
+		assert.Equalf(t, c.expected, stdout, "%q", c.args)
+	}
+
+	_, stderr, _ := runCommand(t, "", "validate", "0 25 * * *")
+	assert.Equal(t, "\"0 25 * * *\": syntax error in hour field: '25'\n", stderr)
+}
@@ -0,0 +1,79 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/explain.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+func runExplain(args []string) int {
+	var f exprFlags
+	fs := newFlagSet("explain", `[options] "{expression}"`)
+	f.register(fs)
+	if code, ok := parseFlags(fs, args, 1, 1); !ok {
+		return code
+	}
+	inTime, err := f.parseTime()
+	if err != nil {
+		return failUsage(err)
+	}
+	expr, err := f.parseExpr(fs.Arg(0))
+	if err != nil {
+		return failInvalid(err)
+	}
+
+	fmt.Printf("expression:  %s\n", fs.Arg(0))
+	fmt.Printf("normalized:  %s\n", expr.Normalized())
+	fmt.Printf("description: %s\n", expr.Describe())
+	if next := expr.Next(inTime); !next.IsZero() {
+		fmt.Printf("next:        %s\n", next.Format(time.RFC3339))
+	} else {
+		fmt.Printf("next:        never\n")
+	}
+	for _, warning := range cronexpr.Lint(expr, inTime.Location()) {
+		fmt.Printf("warning:     %s\n", warning)
+	}
+	return exitOK
+}
+
+// runValidate checks each expression, strictly so that expressions which
+// never fire are invalid.
+func runValidate(args []string) int {
+	var f exprFlags
+	fs := newFlagSet("validate", `[options] "{expression}"...`)
+	fs.BoolVar(&f.systemd, "systemd", false, `validate systemd calendar events (i.e. "Mon..Fri *-*-* 09:00") instead of cron expressions`)
+	if code, ok := parseFlags(fs, args, 1, -1); !ok {
+		return code
+	}
+
+	code := exitOK
+	for _, s := range fs.Args() {
+		var err error
+		if f.systemd {
+			_, err = cronexpr.ParseSystemdStrict(s)
+		} else {
+			_, err = cronexpr.ParseStrict(s)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\"%s\": %s\n", s, err)
+			code = exitInvalid
+			continue
+		}
+		fmt.Printf("\"%s\": valid\n", s)
+	}
+	return code
+}
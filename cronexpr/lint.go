@@ -0,0 +1,187 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/lint.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+	"github.com/WinnerSoftLab/cronexpr/crontab"
+)
+
+/******************************************************************************/
+
+// A diagnostic is an error or a warning reported by `lint`.
+type diagnostic struct {
+	line int
+	// column is 0 for warnings, which are about a whole schedule.
+	column  int
+	warning bool
+	message string
+}
+
+func (d diagnostic) format(name string) string {
+	if d.warning {
+		return fmt.Sprintf("%s:%d: warning: %s", name, d.line, d.message)
+	}
+	return fmt.Sprintf("%s:%d:%d: error: %s", name, d.line, d.column, d.message)
+}
+
+// lintFlags are the options of the `lint` command.
+type lintFlags struct {
+	system bool
+	strict bool
+	tz     string
+}
+
+/******************************************************************************/
+
+// runLint checks crontab files, or expressions read from the standard input
+// one per line, and reports malformed schedules as errors and suspicious ones
+// as warnings.
+func runLint(args []string) int {
+	var f lintFlags
+	fs := newFlagSet("lint", `[options] [FILE...]`)
+	fs.BoolVar(&f.system, "system", false, `read system crontabs, which have a user column before the command, as are /etc/crontab and the files of /etc/cron.d without this option`)
+	fs.BoolVar(&f.strict, "strict", false, `exit with an error status on warnings too`)
+	fs.StringVar(&f.tz, "tz", "", `IANA time zone (i.e. "America/New_York") in which daylight saving gaps are looked for, the local time zone if not present`)
+	if code, ok := parseFlags(fs, args, 0, -1); !ok {
+		return code
+	}
+	loc := time.Local
+	if f.tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(f.tz); err != nil {
+			return failUsage(fmt.Errorf("unknown time zone: \"%s\"", f.tz))
+		}
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	var errorCount, warningCount int
+	for _, name := range names {
+		var diagnostics []diagnostic
+		var err error
+		if name == "-" {
+			name = "<stdin>"
+			diagnostics, err = lintExpressions(os.Stdin, loc)
+		} else {
+			diagnostics, err = lintFile(name, f.system || isSystemCrontab(name), loc)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "# error: %s\n", err)
+			errorCount += 1
+			continue
+		}
+		for _, d := range diagnostics {
+			fmt.Println(d.format(name))
+			if d.warning {
+				warningCount += 1
+			} else {
+				errorCount += 1
+			}
+		}
+	}
+	if errorCount > 0 || f.strict && warningCount > 0 {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// isSystemCrontab tells whether the file at `name` is a system crontab by its
+// location.
+func isSystemCrontab(name string) bool {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	return abs == "/etc/crontab" || filepath.Dir(abs) == "/etc/cron.d"
+}
+
+/******************************************************************************/
+
+// lintFile checks the crontab file at `name`.
+func lintFile(name string, system bool, loc *time.Location) ([]diagnostic, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tab *crontab.Crontab
+	var parseErrors []*crontab.ParseError
+	if system {
+		tab, parseErrors, err = crontab.ParseSystemAll(file)
+	} else {
+		tab, parseErrors, err = crontab.ParseAll(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var diagnostics []diagnostic
+	for _, perr := range parseErrors {
+		diagnostics = append(diagnostics, diagnostic{line: perr.Line, column: perr.Column, message: perr.Err.Error()})
+	}
+	for _, entry := range tab.Entries {
+		diagnostics = append(diagnostics, warnings(entry.Line, entry.Schedule, loc)...)
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].line < diagnostics[j].line
+	})
+	return diagnostics, nil
+}
+
+// lintExpressions checks the cron expressions read from `r`, one per line.
+// Blank lines and lines starting with '#' are skipped.
+func lintExpressions(r io.Reader, loc *time.Location) ([]diagnostic, error) {
+	var diagnostics []diagnostic
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo += 1
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		expr, err := cronexpr.Parse(line)
+		if err != nil {
+			column := strings.Index(scanner.Text(), line) + 1
+			var serr *cronexpr.SyntaxError
+			if errors.As(err, &serr) {
+				column += serr.Offset
+			}
+			diagnostics = append(diagnostics, diagnostic{line: lineNo, column: column, message: err.Error()})
+			continue
+		}
+		diagnostics = append(diagnostics, warnings(lineNo, expr, loc)...)
+	}
+	return diagnostics, scanner.Err()
+}
+
+// warnings returns the Lint warnings about `expr`, found at `line`.
+func warnings(line int, expr *cronexpr.Expression, loc *time.Location) []diagnostic {
+	var diagnostics []diagnostic
+	for _, warning := range cronexpr.Lint(expr, loc) {
+		diagnostics = append(diagnostics, diagnostic{line: line, warning: true, message: fmt.Sprintf("%s (%s)", warning, warning.Kind)})
+	}
+	return diagnostics
+}
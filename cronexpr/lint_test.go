@@ -0,0 +1,57 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/lint_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/******************************************************************************/
+
+func TestLintExpressions(t *testing.T) {
+	stdin := "0 0 * * *\n# comment\n\n  0 25 * * *\n0 0 30 2 *\n"
+	stdout, _, code := runCommand(t, stdin, "lint", "-tz", "UTC")
+	assert.Equal(t, exitInvalid, code)
+	assert.Equal(t, "<stdin>:4:5: error: syntax error in hour field: '25'\n"+
+		"<stdin>:5: warning: expression never fires (never-fires)\n", stdout)
+
+	// Columns are in the line as written, aliases unexpanded
+	stdout, _, _ = runCommand(t, "0 0 0 @daily\n", "lint", "-tz", "UTC")
+	assert.Equal(t, "<stdin>:1:7: error: syntax error in day-of-month field: '0'\n", stdout)
+}
+
+func TestLintFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "crontab")
+	require.NoError(t, os.WriteFile(name, []byte("MAILTO=root\n0 0 * * * true\n30 2 * * * backup\n"), 0o600))
+
+	stdout, _, code := runCommand(t, "", "lint", "-tz", "America/New_York", name)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, name+":3: warning: 02:30:00 does not exist on 1970-04-26 in America/New_York because of daylight saving (daylight-saving-gap)\n", stdout)
+
+	_, _, code = runCommand(t, "", "lint", "-strict", "-tz", "America/New_York", name)
+	assert.Equal(t, exitInvalid, code)
+	_, _, code = runCommand(t, "", "lint", "-tz", "UTC", name)
+	assert.Equal(t, exitOK, code)
+
+	require.NoError(t, os.WriteFile(name, []byte("0 0 * * * true\n0 61 * * * false\n"), 0o600))
+	stdout, _, code = runCommand(t, "", "lint", "-tz", "UTC", name)
+	assert.Equal(t, exitInvalid, code)
+	assert.Equal(t, name+":2:3: error: syntax error in hour field: '61'\n", stdout)
+
+	_, stderr, code := runCommand(t, "", "lint", filepath.Join(t.TempDir(), "missing"))
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stderr, "no such file or directory")
+}
@@ -13,6 +13,7 @@ package main
 /******************************************************************************/
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -23,52 +24,187 @@ import (
 
 /******************************************************************************/
 
-var (
-	usage = func() {
-		fmt.Fprintf(os.Stderr, "usage:\n  %s [options] \"{cron expression}\"\noptions:\n", os.Args[0])
-		flag.PrintDefaults()
-	}
-	inTimeStr     string
-	outTimeCount  uint
-	outTimeLayout string
+// Exit codes
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+	exitDiffer  = 3
+)
+
+// systemdLayout is the time layout of `systemd-analyze calendar`.
+const systemdLayout = "Mon 2006-01-02 15:04:05 MST"
+
+// defaultLayout is the time layout of time values in the text output, see the
+// -l option, to which offsetLayout is appended unless -offset=false.
+const (
+	defaultLayout = "Mon, 02 Jan 2006 15:04:05 MST"
+	offsetLayout  = " -07:00"
 )
 
 /******************************************************************************/
 
+// A command is a subcommand of the tool, e.g. `cronexpr next`.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"next", `[options] "{expression}"`, "output the next time values matched by an expression", runNext},
+		{"prev", `[options] "{expression}"`, "output the previous time values matched by an expression", runPrev},
+		{"between", `[options] "{expression}" FROM TO`, "output the time values matched by an expression from FROM up to TO", runBetween},
+		{"explain", `[options] "{expression}"`, "describe an expression in English", runExplain},
+		{"validate", `[options] "{expression}"...`, "check that expressions are valid and fire", runValidate},
+		{"cal", `[options] "{expression}" [YYYY-MM]`, "output the calendar of a month with the days matched by an expression", runCal},
+		{"repl", `[options]`, "evaluate expressions and commands read one per line, interactively", runRepl},
+		{"convert", `[options] "{expression}"`, "write an expression in another dialect", runConvert},
+		{"diff", `[options] "{old expression}" "{new expression}"`, "output the time values matched by only one of two expressions, exiting with 3 if any", runDiff},
+		{"serve", `[options]`, "serve JSON endpoints evaluating expressions over HTTP", runServe},
+		{"lint", `[options] [FILE...]`, "check the schedules of crontab files, or expressions read from the standard input", runLint},
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage:\n  %s command [options] [arguments]\n  %s [options] \"{cron expression}\"\ncommands:\n", os.Args[0], os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "Without a command, the time values following the cron expression are output, as with `next`.\n")
+	fmt.Fprintf(os.Stderr, "Run `%s command -h` for the options of a command.\n", os.Args[0])
+}
+
+/******************************************************************************/
+
 func main() {
-	var err error
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+	// Legacy mode, flags and expression without command
+	return runNext(args)
+}
+
+// newFlagSet returns the flag set of the command `name`, which takes
+// `arguments`.
+func newFlagSet(name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage:\n  %s %s %s\noptions:\n", os.Args[0], name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses `args` with `fs`, and checks that the number of remaining
+// arguments is at least `min` and at most `max`, if not negative. It returns
+// the exit code of the command if it is to stop there.
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) (int, bool) {
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return exitOK, false
+	} else if err != nil {
+		return exitUsage, false
+	}
+	if fs.NArg() < min || max >= 0 && fs.NArg() > max {
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
 
-	flag.Usage = usage
-	flag.StringVar(&inTimeStr, "t", "", `whole or partial RFC3339 time value (i.e. "2006-01-02T15:04:05Z07:00") against which the cron expression is evaluated, now if not present`)
-	flag.UintVar(&outTimeCount, "n", 1, `number of resulting time values to output`)
-	flag.StringVar(&outTimeLayout, "l", "Mon, 02 Jan 2006 15:04:05 MST", `Go-compliant time layout to use for outputting time value(s), see <http://golang.org/pkg/time/#pkg-constants>`)
-	flag.Parse()
-
-	cronStr := flag.Arg(0)
-	if len(cronStr) == 0 {
-		flag.Usage()
-		return
-	}
-
-	inTime := time.Now()
-	inTimeLayout := ""
-	timeStrLen := len(inTimeStr)
-	if timeStrLen == 2 {
-		inTimeLayout = "06"
-	} else if timeStrLen >= 4 {
-		inTimeLayout += "2006"
-		if timeStrLen >= 7 {
-			inTimeLayout += "-01"
-			if timeStrLen >= 10 {
-				inTimeLayout += "-02"
-				if timeStrLen >= 13 {
-					inTimeLayout += "T15"
-					if timeStrLen >= 16 {
-						inTimeLayout += ":04"
-						if timeStrLen >= 19 {
-							inTimeLayout += ":05"
-							if timeStrLen >= 20 {
-								inTimeLayout += "Z07:00"
+/******************************************************************************/
+
+// exprFlags are the options shared by commands evaluating expressions.
+type exprFlags struct {
+	time    string
+	tz      string
+	systemd bool
+}
+
+func (f *exprFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.time, "t", "", `whole or partial RFC3339 time value (i.e. "2006-01-02T15:04:05Z07:00") against which the expression is evaluated, now if not present`)
+	fs.StringVar(&f.tz, "tz", "", `IANA time zone (i.e. "America/New_York") in which the expression is evaluated and time values are output, the local time zone if not present`)
+	fs.BoolVar(&f.systemd, "systemd", false, `evaluate a systemd calendar event (i.e. "Mon..Fri *-*-* 09:00") instead of a cron expression`)
+}
+
+// location returns the time zone of the -tz option, nil if none.
+func (f *exprFlags) location() (*time.Location, error) {
+	if f.tz == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(f.tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone: \"%s\"", f.tz)
+	}
+	return loc, nil
+}
+
+// parseTime returns the time value of the -t option, the current time if
+// none, in the time zone of the -tz option if any.
+func (f *exprFlags) parseTime() (time.Time, error) {
+	loc, err := f.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if f.time == "" {
+		if loc != nil {
+			return time.Now().In(loc), nil
+		}
+		return time.Now(), nil
+	}
+	return parseTime(f.time, loc)
+}
+
+// parseExpr parses `s` as a cron expression or as a systemd calendar event,
+// as told by the -systemd option.
+func (f *exprFlags) parseExpr(s string) (*cronexpr.Expression, error) {
+	if f.systemd {
+		return cronexpr.ParseSystemd(s)
+	}
+	return cronexpr.Parse(s)
+}
+
+// parseTime parses a whole or partial RFC3339 time value, in `loc` unless it
+// has an offset. If `loc` is nil, the local time zone is used instead, and
+// time values with an offset are left in their own zone.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	layout := ""
+	n := len(s)
+	if n == 2 {
+		layout = "06"
+	} else if n >= 4 {
+		layout += "2006"
+		if n >= 7 {
+			layout += "-01"
+			if n >= 10 {
+				layout += "-02"
+				if n >= 13 {
+					layout += "T15"
+					if n >= 16 {
+						layout += ":04"
+						if n >= 19 {
+							layout += ":05"
+							if n >= 20 {
+								layout += "Z07:00"
 							}
 						}
 					}
@@ -76,36 +212,42 @@ func main() {
 			}
 		}
 	}
+	if layout == "" {
+		return time.Time{}, fmt.Errorf("unparseable time value: \"%s\"", s)
+	}
 
-	if len(inTimeLayout) > 0 {
+	var t time.Time
+	var err error
+	if n < 20 {
 		// default to local time zone
-		if timeStrLen < 20 {
-			inTime, err = time.ParseInLocation(inTimeLayout, inTimeStr, time.Local)
+		if loc == nil {
+			t, err = time.ParseInLocation(layout, s, time.Local)
 		} else {
-			inTime, err = time.Parse(inTimeLayout, inTimeStr)
+			t, err = time.ParseInLocation(layout, s, loc)
 		}
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "# error: unparseable time value: \"%s\"\n", inTimeStr)
-			os.Exit(1)
+	} else {
+		t, err = time.Parse(layout, s)
+		if loc != nil {
+			t = t.In(loc)
 		}
 	}
-
-	expr, err := cronexpr.Parse(cronStr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "# %s: %s\n", os.Args[0], err)
-		os.Exit(1)
+		return time.Time{}, fmt.Errorf("unparseable time value: \"%s\"", s)
 	}
+	return t, nil
+}
 
-	// Anything on the output which starts with '#' can be ignored if the caller
-	// is interested only in the time values. There is only one time
-	// value per line, and they are always in chronological ascending order.
-	fmt.Printf("# \"%s\" + \"%s\" =\n", cronStr, inTime.Format(time.RFC3339))
+/******************************************************************************/
 
-	if outTimeCount < 1 {
-		outTimeCount = 1
-	}
-	outTimes := expr.NextN(inTime, outTimeCount)
-	for _, outTime := range outTimes {
-		fmt.Println(outTime.Format(outTimeLayout))
-	}
+// failUsage reports a bad argument and returns the usage exit code.
+func failUsage(err error) int {
+	fmt.Fprintf(os.Stderr, "# error: %s\n", err)
+	return exitUsage
+}
+
+// failInvalid reports an invalid expression and returns the matching exit
+// code.
+func failInvalid(err error) int {
+	fmt.Fprintf(os.Stderr, "# %s: %s\n", os.Args[0], err)
+	return exitInvalid
 }
@@ -0,0 +1,55 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/main_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+/******************************************************************************/
+
+// runCommand runs the tool with `args`, `stdin` as its standard input, and
+// returns what it wrote to its standard output and error along with its exit
+// code.
+func runCommand(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	in := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(in, []byte(stdin), 0o600))
+	inFile, err := os.Open(in)
+	require.NoError(t, err)
+	defer inFile.Close()
+
+	outReader, outWriter, err := os.Pipe()
+	require.NoError(t, err)
+	errReader, errWriter, err := os.Pipe()
+	require.NoError(t, err)
+	stdout, stderr := make(chan string), make(chan string)
+	readAll := func(r *os.File, c chan<- string) {
+		b, _ := io.ReadAll(r)
+		r.Close()
+		c <- string(b)
+	}
+	go readAll(outReader, stdout)
+	go readAll(errReader, stderr)
+
+	savedIn, savedOut, savedErr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = inFile, outWriter, errWriter
+	code := run(args)
+	os.Stdin, os.Stdout, os.Stderr = savedIn, savedOut, savedErr
+	outWriter.Close()
+	errWriter.Close()
+	return <-stdout, <-stderr, code
+}
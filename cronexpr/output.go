@@ -0,0 +1,108 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/output.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+/******************************************************************************/
+
+// Output formats, see the -o option
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+)
+
+// A listing is the result of a command listing time values, as output in the
+// JSON and CSV formats.
+type listing struct {
+	Expression string `json:"expression"`
+	// Time is the time value against which the expression is evaluated, the
+	// start of the range for `between`.
+	Time string `json:"time"`
+	// Until is the end of the range for `between`.
+	Until      string        `json:"until,omitempty"`
+	TimeZone   string        `json:"timezone"`
+	Normalized string        `json:"normalized"`
+	Times      []listingTime `json:"times"`
+	// Truncated is true if Times was cut short by a limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type listingTime struct {
+	Time string `json:"time"`
+	Unix int64  `json:"unix"`
+	// Offset is the UTC offset of the time value, e.g. "-05:00"
+	Offset string `json:"offset"`
+}
+
+func newListing(exprStr, normalized string, inTime time.Time, times []time.Time) *listing {
+	l := &listing{
+		Expression: exprStr,
+		Time:       inTime.Format(time.RFC3339),
+		TimeZone:   zoneName(inTime),
+		Normalized: normalized,
+		Times:      make([]listingTime, len(times)),
+	}
+	for i, t := range times {
+		l.Times[i] = newListingTime(t)
+	}
+	return l
+}
+
+func newListingTime(t time.Time) listingTime {
+	return listingTime{Time: t.Format(time.RFC3339), Unix: t.Unix(), Offset: t.Format("-07:00")}
+}
+
+// zoneName returns the name of the time zone of `t`, its abbreviation if the
+// zone is the local one, which has no IANA name.
+func zoneName(t time.Time) string {
+	if name := t.Location().String(); name != "Local" {
+		return name
+	}
+	name, _ := t.Zone()
+	return name
+}
+
+// write outputs `l` in `format`, which is either JSON or CSV.
+func (l *listing) write(w io.Writer, format string) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(l)
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"expression", "time", "until", "timezone", "normalized", "result", "unix", "offset"})
+		for _, t := range l.Times {
+			cw.Write([]string{l.Expression, l.Time, l.Until, l.TimeZone, l.Normalized, t.Time, strconv.FormatInt(t.Unix, 10), t.Offset})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format: \"%s\"", format)
+}
+
+// checkFormat returns an error if `format` is not a known output format.
+func checkFormat(format string) error {
+	switch format {
+	case outputText, outputJSON, outputCSV:
+		return nil
+	}
+	return fmt.Errorf("unknown output format: \"%s\"", format)
+}
@@ -0,0 +1,73 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/output_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/******************************************************************************/
+
+func TestOutputJSON(t *testing.T) {
+	stdout, _, code := runCommand(t, "", "between", "-tz", "UTC", "-n", "2", "-o", "json", "0 0 * * mon", "2013-09-01", "2013-10-01")
+	require.Equal(t, exitOK, code)
+	var l listing
+	require.NoError(t, json.Unmarshal([]byte(stdout), &l))
+	assert.Equal(t, listing{
+		Expression: "0 0 * * mon",
+		Time:       "2013-09-01T00:00:00Z",
+		Until:      "2013-10-01T00:00:00Z",
+		TimeZone:   "UTC",
+		Normalized: "0 0 * * 1",
+		Times: []listingTime{
+			{Time: "2013-09-02T00:00:00Z", Unix: 1378080000, Offset: "+00:00"},
+			{Time: "2013-09-09T00:00:00Z", Unix: 1378684800, Offset: "+00:00"},
+		},
+	}, l)
+
+	// Expressions which never fire list no time values, rather than null
+	stdout, _, code = runCommand(t, "", "next", "-tz", "UTC", "-t", "2013-09-01", "-o", "json", "0 0 30 2 *")
+	require.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"times": []`)
+}
+
+func TestOutputCSV(t *testing.T) {
+	stdout, _, code := runCommand(t, "", "prev", "-tz", "UTC", "-t", "2013-09-01T00:00:00Z", "-n", "2", "-o", "csv", "0 12 * * *")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, "expression,time,until,timezone,normalized,result,unix,offset\n"+
+		"0 12 * * *,2013-09-01T00:00:00Z,,UTC,0 12 * * *,2013-08-30T12:00:00Z,1377864000,+00:00\n"+
+		"0 12 * * *,2013-09-01T00:00:00Z,,UTC,0 12 * * *,2013-08-31T12:00:00Z,1377950400,+00:00\n", stdout)
+}
+
+func TestOutputOffset(t *testing.T) {
+	// UTC offsets are in the text output unless -offset=false
+	stdout, _, code := runCommand(t, "", "next", "-tz", "America/New_York", "-t", "2013-09-01", "-n", "2", "0 12 15W 3/3 *")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, "# \"0 12 15W 3/3 *\" + \"2013-09-01T00:00:00-04:00\" =\n"+
+		"Mon, 16 Sep 2013 12:00:00 EDT -04:00\n"+
+		"Mon, 16 Dec 2013 12:00:00 EST -05:00\n", stdout)
+
+	stdout, _, code = runCommand(t, "", "next", "-tz", "America/New_York", "-t", "2013-09-01", "-n", "2", "-offset=false", "0 12 15W 3/3 *")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, "# \"0 12 15W 3/3 *\" + \"2013-09-01T00:00:00-04:00\" =\n"+
+		"Mon, 16 Sep 2013 12:00:00 EDT\n"+
+		"Mon, 16 Dec 2013 12:00:00 EST\n", stdout)
+
+	stdout, _, code = runCommand(t, "", "next", "-tz", "America/New_York", "-t", "2013-09-01", "-n", "2", "-o", "csv", "0 12 15W 3/3 *")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, "expression,time,until,timezone,normalized,result,unix,offset\n"+
+		"0 12 15W 3/3 *,2013-09-01T00:00:00-04:00,,America/New_York,0 12 15W 3/3 *,2013-09-16T12:00:00-04:00,1379347200,-04:00\n"+
+		"0 12 15W 3/3 *,2013-09-01T00:00:00-04:00,,America/New_York,0 12 15W 3/3 *,2013-12-16T12:00:00-05:00,1387213200,-05:00\n", stdout)
+}
@@ -0,0 +1,297 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/repl.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// A repl is the state of an interactive session, as changed by its commands.
+type repl struct {
+	out io.Writer
+	// time is the time value against which expressions are evaluated, the
+	// current time if zero.
+	time    time.Time
+	loc     *time.Location
+	systemd bool
+	count   int
+	layout  string
+}
+
+// A replCommand is a command of the interactive session, e.g. `:tz UTC`.
+type replCommand struct {
+	name    string
+	args    string
+	summary string
+	run     func(r *repl, arg string) error
+}
+
+var replCommands []replCommand
+
+// errQuit is returned by the `:quit` command to end the session.
+var errQuit = errors.New("quit")
+
+func init() {
+	replCommands = []replCommand{
+		{"time", "[TIME|now]", "set or show the time value against which expressions are evaluated", (*repl).setTime},
+		{"tz", "[ZONE|local]", "set or show the time zone in which expressions are evaluated", (*repl).setZone},
+		{"dialect", "[cron|systemd]", "set or show the syntax of expressions", (*repl).setDialect},
+		{"n", "[COUNT]", "set or show the number of time values to output", (*repl).setCount},
+		{"help", "", "list the commands", (*repl).help},
+		{"quit", "", "end the session", func(*repl, string) error { return errQuit }},
+	}
+}
+
+/******************************************************************************/
+
+func runRepl(args []string) int {
+	var f exprFlags
+	var count uint
+	var offset bool
+	fs := newFlagSet("repl", "[options]")
+	f.register(fs)
+	fs.UintVar(&count, "n", 5, `number of time values to output for each expression`)
+	fs.BoolVar(&offset, "offset", true, `append the UTC offset of each time value (i.e. "-05:00"), so that daylight saving changes are visible, -offset=false to leave it out`)
+	if code, ok := parseFlags(fs, args, 0, 0); !ok {
+		return code
+	}
+	if count < 1 {
+		count = 1
+	}
+	r := &repl{out: os.Stdout, systemd: f.systemd, count: int(count), layout: defaultLayout}
+	if offset {
+		r.layout += offsetLayout
+	}
+	loc, err := f.location()
+	if err != nil {
+		return failUsage(err)
+	}
+	r.loc = loc
+	if f.time != "" {
+		if r.time, err = parseTime(f.time, loc); err != nil {
+			return failUsage(err)
+		}
+	}
+
+	interactive := isTerminal(os.Stdin)
+	if interactive {
+		fmt.Fprintf(r.out, "Enter an expression, or :help for the list of commands.\n")
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		if interactive {
+			fmt.Fprintf(r.out, "%s> ", r.dialect())
+		}
+		if !scanner.Scan() {
+			break
+		}
+		if err := r.eval(scanner.Text()); errors.Is(err, errQuit) {
+			return exitOK
+		} else if err != nil {
+			fmt.Fprintf(r.out, "error: %s\n", err)
+		}
+	}
+	if interactive {
+		fmt.Fprintln(r.out)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "# error: %s\n", err)
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// eval runs a command, if `line` starts with ':', or else evaluates an
+// expression.
+func (r *repl) eval(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || line[0] == '#' {
+		return nil
+	}
+	if line[0] != ':' {
+		r.evalExpression(line)
+		return nil
+	}
+	name, arg, _ := strings.Cut(line[1:], " ")
+	for _, cmd := range replCommands {
+		if cmd.name == name {
+			return cmd.run(r, strings.TrimSpace(arg))
+		}
+	}
+	return fmt.Errorf("unknown command: \":%s\", see :help", name)
+}
+
+// evalExpression outputs the description of `s` and its next time values, or
+// where it is malformed.
+func (r *repl) evalExpression(s string) {
+	var expr *cronexpr.Expression
+	var err error
+	if r.systemd {
+		expr, err = cronexpr.ParseSystemd(s)
+	} else {
+		expr, err = cronexpr.Parse(s)
+	}
+	if err != nil {
+		var serr *cronexpr.SyntaxError
+		if errors.As(err, &serr) {
+			fmt.Fprintf(r.out, "  %s\n  %s\n", s, caret(s, serr))
+		}
+		fmt.Fprintf(r.out, "error: %s\n", err)
+		return
+	}
+
+	now := r.now()
+	fmt.Fprintf(r.out, "normalized:  %s\n", expr.Normalized())
+	fmt.Fprintf(r.out, "description: %s\n", expr.Describe())
+	for _, warning := range cronexpr.Lint(expr, now.Location()) {
+		fmt.Fprintf(r.out, "warning:     %s\n", warning)
+	}
+	times := expr.NextN(now, uint(r.count))
+	if len(times) == 0 {
+		fmt.Fprintf(r.out, "next:        never\n")
+	}
+	for i, t := range times {
+		label := ""
+		if i == 0 {
+			label = "next:"
+		}
+		fmt.Fprintf(r.out, "%-12s %s\n", label, t.Format(r.layout))
+	}
+}
+
+// caret returns a line pointing at the token of `serr` in `s`, e.g. "   ^^"
+// under "0 25 * * *".
+func caret(s string, serr *cronexpr.SyntaxError) string {
+	offset := serr.Offset
+	if offset > len(s) {
+		offset = len(s)
+	}
+	width := len(serr.Token)
+	if width == 0 {
+		width = 1
+	}
+	// Keep tabs so that the caret lines up with the expression
+	var b strings.Builder
+	for _, c := range s[:offset] {
+		if c == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String() + strings.Repeat("^", width)
+}
+
+/******************************************************************************/
+
+// now returns the time value against which expressions are evaluated.
+func (r *repl) now() time.Time {
+	t := r.time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	if r.loc != nil {
+		t = t.In(r.loc)
+	}
+	return t
+}
+
+func (r *repl) dialect() string {
+	if r.systemd {
+		return "systemd"
+	}
+	return "cron"
+}
+
+func (r *repl) setTime(arg string) error {
+	switch arg {
+	case "":
+	case "now":
+		r.time = time.Time{}
+	default:
+		t, err := parseTime(arg, r.loc)
+		if err != nil {
+			return err
+		}
+		r.time = t
+	}
+	if r.time.IsZero() {
+		fmt.Fprintf(r.out, "time: now (%s)\n", r.now().Format(time.RFC3339))
+	} else {
+		fmt.Fprintf(r.out, "time: %s\n", r.now().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (r *repl) setZone(arg string) error {
+	switch arg {
+	case "":
+	case "local":
+		r.loc = nil
+	default:
+		loc, err := time.LoadLocation(arg)
+		if err != nil {
+			return fmt.Errorf("unknown time zone: \"%s\"", arg)
+		}
+		r.loc = loc
+	}
+	if r.loc == nil {
+		fmt.Fprintf(r.out, "tz: local (%s)\n", zoneName(r.now()))
+	} else {
+		fmt.Fprintf(r.out, "tz: %s\n", r.loc)
+	}
+	return nil
+}
+
+func (r *repl) setDialect(arg string) error {
+	switch arg {
+	case "":
+	case "cron":
+		r.systemd = false
+	case "systemd":
+		r.systemd = true
+	default:
+		return fmt.Errorf("unknown dialect: \"%s\", must be cron or systemd", arg)
+	}
+	fmt.Fprintf(r.out, "dialect: %s\n", r.dialect())
+	return nil
+}
+
+func (r *repl) setCount(arg string) error {
+	if arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid count: \"%s\"", arg)
+		}
+		r.count = n
+	}
+	fmt.Fprintf(r.out, "n: %d\n", r.count)
+	return nil
+}
+
+func (r *repl) help(string) error {
+	for _, cmd := range replCommands {
+		fmt.Fprintf(r.out, "  %-24s %s\n", ":"+strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+	}
+	fmt.Fprintf(r.out, "Any other line is evaluated as an expression.\n")
+	return nil
+}
@@ -0,0 +1,102 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/repl_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/WinnerSoftLab/cronexpr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/******************************************************************************/
+
+func TestReplEval(t *testing.T) {
+	var out strings.Builder
+	r := &repl{out: &out, count: 2, layout: defaultLayout}
+	cases := []struct {
+		line     string
+		expected string
+		err      string
+	}{
+		{"", "", ""},
+		{"# comment", "", ""},
+		{":tz UTC", "tz: UTC\n", ""},
+		{":time 2013-09-01", "time: 2013-09-01T00:00:00Z\n", ""},
+		{":n", "n: 2\n", ""},
+		{":n 0", "", `invalid count: "0"`},
+		{":tz Mars/Base", "", `unknown time zone: "Mars/Base"`},
+		{":dialect fcron", "", `unknown dialect: "fcron", must be cron or systemd`},
+		{":bogus", "", `unknown command: ":bogus", see :help`},
+		{"0 12 * * mon", "normalized:  0 12 * * 1\n" +
+			"description: at 12:00, on Monday\n" +
+			"next:        Mon, 02 Sep 2013 12:00:00 UTC\n" +
+			"             Mon, 09 Sep 2013 12:00:00 UTC\n", ""},
+		{"0 0 30 2 *", "normalized:  0 0 30 2 *\n" +
+			"description: never\n" +
+			"warning:     expression never fires\n" +
+			"next:        never\n", ""},
+		{"0 25 * * *", "  0 25 * * *\n" +
+			"    ^^\n" +
+			"error: syntax error in hour field: '25'\n", ""},
+		{":dialect systemd", "dialect: systemd\n", ""},
+		{"  Mon 12:00  ", "normalized:  Mon *-*-* 12:00:00\n" +
+			"description: at 12:00, on Monday\n" +
+			"next:        Mon, 02 Sep 2013 12:00:00 UTC\n" +
+			"             Mon, 09 Sep 2013 12:00:00 UTC\n", ""},
+	}
+	for _, c := range cases {
+		out.Reset()
+		err := r.eval(c.line)
+		if c.err == "" {
+			assert.NoErrorf(t, err, "eval(%q)", c.line)
+		} else {
+			assert.EqualErrorf(t, err, c.err, "eval(%q)", c.line)
+		}
+		assert.Equalf(t, c.expected, out.String(), "eval(%q)", c.line)
+	}
+	assert.True(t, errors.Is(r.eval(":quit"), errQuit))
+}
+
+func TestReplSession(t *testing.T) {
+	stdin := ":tz UTC\n:time 2013-09-01\n0 12 * * *\n:quit\n0 13 * * *\n"
+	stdout, _, code := runCommand(t, stdin, "repl", "-n", "1")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "tz: UTC\n"+
+		"time: 2013-09-01T00:00:00Z\n"+
+		"normalized:  0 12 * * *\n"+
+		"description: at 12:00, every day\n"+
+		"next:        Sun, 01 Sep 2013 12:00:00 UTC +00:00\n", stdout)
+}
+
+func TestCaret(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected string
+	}{
+		{"0 25 * * *", "  ^^"},
+		{"0\t25 * * *", " \t^^"},
+		{"* * *", "     ^"},
+		{"0 0 0 @daily", "      ^^^^^^"},
+	}
+	for _, c := range cases {
+		_, err := cronexpr.Parse(c.s)
+		var serr *cronexpr.SyntaxError
+		require.Truef(t, errors.As(err, &serr), "Parse(%q) returned %v", c.s, err)
+		assert.Equalf(t, c.expected, caret(c.s, serr), "caret(%q)", c.s)
+	}
+
+	// Offsets beyond the expression are clamped
+	assert.Equal(t, "  ^", caret("0 ", &cronexpr.SyntaxError{Offset: 5}))
+}
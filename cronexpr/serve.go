@@ -0,0 +1,346 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/serve.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// maxRequestSize is the largest request body accepted by `serve`.
+const maxRequestSize = 64 << 10
+
+// A serveRequest holds the parameters of a request to `serve`, from the JSON
+// body of a POST request or from the query string of a GET request.
+type serveRequest struct {
+	Expression string `json:"expression"`
+	// Dialect is the syntax of Expression, cron by default.
+	Dialect string `json:"dialect"`
+	// Time is the time value against which Expression is evaluated, now by
+	// default.
+	Time string `json:"time"`
+	TZ   string `json:"tz"`
+	N    int    `json:"n"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// A serveError is the body of an error response. Field, Offset and Token
+// locate the offending token of a malformed expression.
+type serveError struct {
+	Error  string `json:"error"`
+	Field  string `json:"field,omitempty"`
+	Offset *int   `json:"offset,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+// A server serves the evaluation of expressions over HTTP, within limits on
+// the number of time values and the size of time ranges of a request.
+type server struct {
+	maxCount  int
+	maxWindow time.Duration
+}
+
+/******************************************************************************/
+
+// runServe serves JSON endpoints evaluating expressions, until interrupted.
+func runServe(args []string) int {
+	var addr string
+	s := &server{}
+	fs := newFlagSet("serve", "[options]")
+	fs.StringVar(&addr, "addr", "localhost:8080", `address to listen on`)
+	fs.IntVar(&s.maxCount, "max-n", 1000, `maximum number of time values of a request`)
+	fs.DurationVar(&s.maxWindow, "max-window", 366*24*time.Hour, `maximum time range of a request to /between`)
+	if code, ok := parseFlags(fs, args, 0, 0); !ok {
+		return code
+	}
+	if s.maxCount < 1 || s.maxWindow <= 0 {
+		return failUsage(errors.New("-max-n and -max-window must be positive"))
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "# listening on %s\n", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "# error: %s\n", err)
+		return exitInvalid
+	}
+	return exitOK
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/parse", s.endpoint(s.parse))
+	mux.HandleFunc("/v1/validate", s.endpoint(s.validate))
+	mux.HandleFunc("/v1/next", s.endpoint(s.next))
+	mux.HandleFunc("/v1/prev", s.endpoint(s.prev))
+	mux.HandleFunc("/v1/between", s.endpoint(s.between))
+	mux.HandleFunc("/v1/describe", s.endpoint(s.describe))
+	mux.HandleFunc("/v1/lint", s.endpoint(s.lint))
+	return mux
+}
+
+// endpoint returns the HTTP handler of `fn`, which returns the body of the
+// response, or the status and body of an error response.
+func (s *server) endpoint(fn func(req *serveRequest) (interface{}, int, *serveError)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req serveRequest
+		switch r.Method {
+		case http.MethodGet:
+			query := r.URL.Query()
+			req = serveRequest{
+				Expression: query.Get("expression"),
+				Dialect:    query.Get("dialect"),
+				Time:       query.Get("time"),
+				TZ:         query.Get("tz"),
+				From:       query.Get("from"),
+				To:         query.Get("to"),
+			}
+			if n := query.Get("n"); n != "" {
+				var err error
+				if req.N, err = strconv.Atoi(n); err != nil {
+					writeJSON(w, http.StatusBadRequest, &serveError{Error: fmt.Sprintf("invalid n: \"%s\"", n)})
+					return
+				}
+			}
+		case http.MethodPost:
+			dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, &serveError{Error: fmt.Sprintf("invalid request: %s", err)})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, &serveError{Error: "method not allowed"})
+			return
+		}
+		body, status, serr := fn(&req)
+		if serr != nil {
+			writeJSON(w, status, serr)
+			return
+		}
+		writeJSON(w, http.StatusOK, body)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+/******************************************************************************/
+
+// expression parses the expression of `req`, and returns it along with the
+// time value against which it is evaluated.
+func (s *server) expression(req *serveRequest) (*cronexpr.Expression, time.Time, int, *serveError) {
+	if req.Expression == "" {
+		return nil, time.Time{}, http.StatusBadRequest, &serveError{Error: "missing expression"}
+	}
+	dialect := cronexpr.Cron
+	if req.Dialect != "" {
+		var err error
+		if dialect, err = cronexpr.LookupDialect(req.Dialect); err != nil {
+			return nil, time.Time{}, http.StatusBadRequest, &serveError{Error: err.Error()}
+		}
+	}
+	f := exprFlags{time: req.Time, tz: req.TZ}
+	t, err := f.parseTime()
+	if err != nil {
+		return nil, time.Time{}, http.StatusBadRequest, &serveError{Error: err.Error()}
+	}
+	expr, err := cronexpr.ParseDialect(req.Expression, dialect)
+	if err != nil {
+		return nil, time.Time{}, http.StatusUnprocessableEntity, newServeError(err)
+	}
+	return expr, t, 0, nil
+}
+
+// newServeError returns the body of the error response for `err`, located in
+// the expression if it is a *cronexpr.SyntaxError.
+func newServeError(err error) *serveError {
+	serr := &serveError{Error: err.Error()}
+	var syntaxErr *cronexpr.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset := syntaxErr.Offset
+		serr.Field, serr.Offset, serr.Token = syntaxErr.Field, &offset, syntaxErr.Token
+	}
+	return serr
+}
+
+// count returns the number of time values of `req`, `byDefault` if not given.
+func (s *server) count(req *serveRequest, byDefault int) (int, *serveError) {
+	if req.N == 0 {
+		return byDefault, nil
+	}
+	if req.N < 0 || req.N > s.maxCount {
+		return 0, &serveError{Error: fmt.Sprintf("n must be between 1 and %d", s.maxCount)}
+	}
+	return req.N, nil
+}
+
+/******************************************************************************/
+
+func (s *server) parse(req *serveRequest) (interface{}, int, *serveError) {
+	expr, _, status, serr := s.expression(req)
+	if serr != nil {
+		return nil, status, serr
+	}
+	return map[string]interface{}{
+		"expression":  req.Expression,
+		"normalized":  expr.Normalized(),
+		"satisfiable": expr.IsSatisfiable(),
+	}, 0, nil
+}
+
+// validate is like parse, but it reports invalid expressions, including those
+// which never fire, with a successful response.
+func (s *server) validate(req *serveRequest) (interface{}, int, *serveError) {
+	expr, _, status, serr := s.expression(req)
+	if serr == nil && !expr.IsSatisfiable() {
+		serr = &serveError{Error: cronexpr.ErrNeverFires.Error()}
+	} else if status == http.StatusBadRequest {
+		return nil, status, serr
+	}
+	return struct {
+		Expression string `json:"expression"`
+		Valid      bool   `json:"valid"`
+		*serveError
+	}{req.Expression, serr == nil, serr}, 0, nil
+}
+
+func (s *server) next(req *serveRequest) (interface{}, int, *serveError) {
+	expr, t, status, serr := s.expression(req)
+	if serr != nil {
+		return nil, status, serr
+	}
+	n, serr := s.count(req, 1)
+	if serr != nil {
+		return nil, http.StatusBadRequest, serr
+	}
+	return newListing(req.Expression, expr.Normalized(), t, expr.NextN(t, uint(n))), 0, nil
+}
+
+func (s *server) prev(req *serveRequest) (interface{}, int, *serveError) {
+	expr, t, status, serr := s.expression(req)
+	if serr != nil {
+		return nil, status, serr
+	}
+	n, serr := s.count(req, 1)
+	if serr != nil {
+		return nil, http.StatusBadRequest, serr
+	}
+	var times []time.Time
+	for prev := expr.Prev(t); !prev.IsZero() && len(times) < n; prev = expr.Prev(prev) {
+		times = append(times, prev)
+	}
+	// Chronological ascending order, as for other endpoints
+	for i, j := 0, len(times)-1; i < j; i, j = i+1, j-1 {
+		times[i], times[j] = times[j], times[i]
+	}
+	return newListing(req.Expression, expr.Normalized(), t, times), 0, nil
+}
+
+// between lists the time values from `from` up to `to`, within the limits of
+// the server: up to -max-window apart, and at most `n` time values, -max-n by
+// default, the listing being truncated beyond.
+func (s *server) between(req *serveRequest) (interface{}, int, *serveError) {
+	expr, _, status, serr := s.expression(req)
+	if serr != nil {
+		return nil, status, serr
+	}
+	n, serr := s.count(req, s.maxCount)
+	if serr != nil {
+		return nil, http.StatusBadRequest, serr
+	}
+	if req.From == "" || req.To == "" {
+		return nil, http.StatusBadRequest, &serveError{Error: "missing from or to"}
+	}
+	f := exprFlags{tz: req.TZ}
+	loc, _ := f.location()
+	from, err := parseTime(req.From, loc)
+	if err != nil {
+		return nil, http.StatusBadRequest, &serveError{Error: err.Error()}
+	}
+	to, err := parseTime(req.To, loc)
+	if err != nil {
+		return nil, http.StatusBadRequest, &serveError{Error: err.Error()}
+	}
+	if to.Sub(from) > s.maxWindow {
+		return nil, http.StatusBadRequest, &serveError{Error: fmt.Sprintf("from and to must be at most %s apart", s.maxWindow)}
+	}
+
+	times := between(expr, from, to, uint(n)+1)
+	l := newListing(req.Expression, expr.Normalized(), from, times)
+	l.Until = to.Format(time.RFC3339)
+	if len(times) > n {
+		l.Times, l.Truncated = l.Times[:n], true
+	}
+	return l, 0, nil
+}
+
+func (s *server) describe(req *serveRequest) (interface{}, int, *serveError) {
+	expr, _, status, serr := s.expression(req)
+	if serr != nil {
+		return nil, status, serr
+	}
+	return map[string]interface{}{
+		"expression":  req.Expression,
+		"normalized":  expr.Normalized(),
+		"description": expr.Describe(),
+	}, 0, nil
+}
+
+func (s *server) lint(req *serveRequest) (interface{}, int, *serveError) {
+	expr, t, status, serr := s.expression(req)
+	if serr != nil {
+		return nil, status, serr
+	}
+	type warning struct {
+		Kind    string `json:"kind"`
+		Field   string `json:"field,omitempty"`
+		Message string `json:"message"`
+	}
+	warnings := []warning{}
+	for _, w := range cronexpr.Lint(expr, t.Location()) {
+		warnings = append(warnings, warning{Kind: w.Kind.String(), Field: w.Field, Message: w.Message})
+	}
+	return map[string]interface{}{
+		"expression": req.Expression,
+		"warnings":   warnings,
+	}, 0, nil
+}
@@ -0,0 +1,195 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr/serve_test.go
+ * Version: 1.0
+ * License: GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *
+ */
+
+package main
+
+/******************************************************************************/
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/******************************************************************************/
+
+// serveGet requests `path` from `s` with the query `query`, and returns the
+// status and decoded JSON body of the response.
+func serveGet(t *testing.T, s *server, path string, query url.Values) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil)
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return rec.Code, body
+}
+
+func TestServe(t *testing.T) {
+	s := &server{maxCount: 10, maxWindow: 31 * 24 * time.Hour}
+	cases := []struct {
+		name   string
+		path   string
+		query  url.Values
+		status int
+		body   map[string]interface{}
+	}{
+		{
+			"next",
+			"/v1/next",
+			url.Values{"expression": {"0 0 * * mon"}, "time": {"2013-09-01T00:00:00Z"}, "tz": {"UTC"}, "n": {"2"}},
+			http.StatusOK,
+			map[string]interface{}{"expression": "0 0 * * mon", "normalized": "0 0 * * 1", "time": "2013-09-01T00:00:00Z", "timezone": "UTC", "times": []interface{}{
+				map[string]interface{}{"time": "2013-09-02T00:00:00Z", "unix": 1378080000.0, "offset": "+00:00"},
+				map[string]interface{}{"time": "2013-09-09T00:00:00Z", "unix": 1378684800.0, "offset": "+00:00"},
+			}},
+		},
+		{
+			"too many time values",
+			"/v1/next",
+			url.Values{"expression": {"* * * * *"}, "n": {"11"}},
+			http.StatusBadRequest,
+			map[string]interface{}{"error": "n must be between 1 and 10"},
+		},
+		{
+			"negative number of time values",
+			"/v1/prev",
+			url.Values{"expression": {"* * * * *"}, "n": {"-1"}},
+			http.StatusBadRequest,
+			map[string]interface{}{"error": "n must be between 1 and 10"},
+		},
+		{
+			"unparseable number of time values",
+			"/v1/next",
+			url.Values{"expression": {"* * * * *"}, "n": {"ten"}},
+			http.StatusBadRequest,
+			map[string]interface{}{"error": `invalid n: "ten"`},
+		},
+		{
+			"window too large",
+			"/v1/between",
+			url.Values{"expression": {"0 0 * * *"}, "tz": {"UTC"}, "from": {"2013-01-01"}, "to": {"2013-03-01"}},
+			http.StatusBadRequest,
+			map[string]interface{}{"error": "from and to must be at most 744h0m0s apart"},
+		},
+		{
+			"missing expression",
+			"/v1/parse",
+			url.Values{},
+			http.StatusBadRequest,
+			map[string]interface{}{"error": "missing expression"},
+		},
+		{
+			"unknown time zone",
+			"/v1/next",
+			url.Values{"expression": {"* * * * *"}, "tz": {"Mars/Base"}},
+			http.StatusBadRequest,
+			map[string]interface{}{"error": `unknown time zone: "Mars/Base"`},
+		},
+		{
+			"malformed expression",
+			"/v1/parse",
+			url.Values{"expression": {"0 25 * * *"}},
+			http.StatusUnprocessableEntity,
+			map[string]interface{}{"error": "syntax error in hour field: '25'", "field": "hour", "offset": 2.0, "token": "25"},
+		},
+		{
+			"malformed expression with an alias",
+			"/v1/describe",
+			url.Values{"expression": {"0 0 0 @daily"}},
+			http.StatusUnprocessableEntity,
+			map[string]interface{}{"error": "syntax error in day-of-month field: '0'", "field": "day-of-month", "offset": 6.0, "token": "@daily"},
+		},
+		{
+			"unsatisfiable expression",
+			"/v1/parse",
+			url.Values{"expression": {"5-2 * * * * * *"}},
+			http.StatusOK,
+			map[string]interface{}{"expression": "5-2 * * * * * *", "normalized": "5-2 * * * * * *", "satisfiable": false},
+		},
+		{
+			"description of an unsatisfiable expression",
+			"/v1/describe",
+			url.Values{"expression": {"0 0 30 2 *"}},
+			http.StatusOK,
+			map[string]interface{}{"expression": "0 0 30 2 *", "normalized": "0 0 30 2 *", "description": "never"},
+		},
+		{
+			"validation of an unsatisfiable expression",
+			"/v1/validate",
+			url.Values{"expression": {"0 0 30 2 *"}},
+			http.StatusOK,
+			map[string]interface{}{"expression": "0 0 30 2 *", "valid": false, "error": "expression never fires"},
+		},
+		{
+			"validation of a malformed expression",
+			"/v1/validate",
+			url.Values{"expression": {"0 25 * * *"}},
+			http.StatusOK,
+			map[string]interface{}{"expression": "0 25 * * *", "valid": false, "error": "syntax error in hour field: '25'", "field": "hour", "offset": 2.0, "token": "25"},
+		},
+		{
+			"lint",
+			"/v1/lint",
+			url.Values{"expression": {"0 0 30 2 *"}},
+			http.StatusOK,
+			map[string]interface{}{"expression": "0 0 30 2 *", "warnings": []interface{}{
+				map[string]interface{}{"kind": "never-fires", "message": "expression never fires"},
+			}},
+		},
+	}
+	for _, c := range cases {
+		status, body := serveGet(t, s, c.path, c.query)
+		assert.Equalf(t, c.status, status, c.name)
+		assert.Equalf(t, c.body, body, c.name)
+	}
+}
+
+func TestServeBetween(t *testing.T) {
+	s := &server{maxCount: 3, maxWindow: 31 * 24 * time.Hour}
+	query := url.Values{"expression": {"0 0 * * *"}, "tz": {"UTC"}, "from": {"2013-09-01"}, "to": {"2013-09-08"}}
+	status, body := serveGet(t, s, "/v1/between", query)
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, body["times"], 3)
+	assert.Equal(t, true, body["truncated"])
+	assert.Equal(t, "2013-09-08T00:00:00Z", body["until"])
+
+	query.Set("n", "2")
+	status, body = serveGet(t, s, "/v1/between", query)
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, body["times"], 2)
+}
+
+func TestServeMethods(t *testing.T) {
+	s := &server{maxCount: 10, maxWindow: time.Hour}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/describe", strings.NewReader(`{"expression": "@hourly"}`))
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"expression": "@hourly", "normalized": "0 * * * *", "description": "at minute 0 past every hour"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/describe", strings.NewReader(`{"expression": "@hourly", "bogus": 1}`))
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/v1/describe", nil)
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get("Allow"))
+}
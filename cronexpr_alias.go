@@ -0,0 +1,216 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_alias.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/******************************************************************************/
+
+// An Alias is a named shorthand, such as `@daily`, which stands for a whole
+// cron expression or systemd calendar event.
+type Alias struct {
+	Name      string
+	Expansion string
+}
+
+/******************************************************************************/
+
+var (
+	cronAliasNameFinder    = regexp.MustCompile(`^@[A-Za-z0-9_-]+$`)
+	systemdAliasNameFinder = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+)
+
+var cronAliases = newAliasRegistry(map[string]string{
+	"@yearly":   "0 0 0 1 1 * *",
+	"@annually": "0 0 0 1 1 * *",
+	"@monthly":  "0 0 0 1 * * *",
+	"@weekly":   "0 0 0 * * 0 *",
+	"@daily":    "0 0 0 * * * *",
+	"@hourly":   "0 0 * * * * *",
+})
+
+var systemdAliases = newAliasRegistry(map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+})
+
+/******************************************************************************/
+
+// RegisterAlias makes `name`, which must start with `@`, usable in place of
+// the cron expression `expansion` in subsequent calls to Parse. The expansion
+// must itself be a valid cron expression, it may not refer to other aliases.
+//
+// An error is returned if `name` is malformed or already registered.
+// RegisterAlias is safe for concurrent use.
+func RegisterAlias(name, expansion string) error {
+	if !cronAliasNameFinder.MatchString(name) {
+		return fmt.Errorf("invalid alias name '%s'", name)
+	}
+	if strings.EqualFold(name, "@every") || strings.EqualFold(name, "@reboot") {
+		return fmt.Errorf("alias %s is reserved", name)
+	}
+	if _, err := parseCron(expansion); err != nil {
+		return fmt.Errorf("invalid expansion for alias %s: %s", name, err)
+	}
+	return cronAliases.register(name, expansion)
+}
+
+// RegisterSystemdAlias makes `name` usable in place of the systemd calendar
+// event `expansion` in subsequent calls to ParseSystemd. Systemd aliases are
+// case-insensitive, as is the rest of a calendar event. The expansion must
+// itself be a valid calendar event, it may not refer to other aliases.
+//
+// An error is returned if `name` is malformed or already registered.
+// RegisterSystemdAlias is safe for concurrent use.
+func RegisterSystemdAlias(name, expansion string) error {
+	name = strings.ToLower(name)
+	if !systemdAliasNameFinder.MatchString(name) {
+		return fmt.Errorf("invalid alias name '%s'", name)
+	}
+	if _, found := dowTokens[name]; found {
+		return fmt.Errorf("alias %s is reserved", name)
+	}
+	if _, err := parseSystemd(strings.ToLower(expansion)); err != nil {
+		return fmt.Errorf("invalid expansion for alias %s: %s", name, err)
+	}
+	return systemdAliases.register(name, expansion)
+}
+
+// Aliases returns the aliases usable with Parse, built-in ones included,
+// sorted by name.
+func Aliases() []Alias {
+	return cronAliases.list()
+}
+
+// SystemdAliases returns the aliases usable with ParseSystemd, built-in ones
+// included, sorted by name.
+func SystemdAliases() []Alias {
+	return systemdAliases.list()
+}
+
+/******************************************************************************/
+
+type aliasRegistry struct {
+	lock    sync.RWMutex
+	aliases map[string]string
+}
+
+func newAliasRegistry(builtins map[string]string) *aliasRegistry {
+	return &aliasRegistry{aliases: builtins}
+}
+
+func (r *aliasRegistry) register(name, expansion string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, found := r.aliases[name]; found {
+		return fmt.Errorf("alias %s already registered", name)
+	}
+	r.aliases[name] = expansion
+	return nil
+}
+
+// unregister forgets the alias `name`, so that tests leave the registry as
+// they found it.
+func (r *aliasRegistry) unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.aliases, name)
+}
+
+func (r *aliasRegistry) list() []Alias {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	aliases := make([]Alias, 0, len(r.aliases))
+	for name, expansion := range r.aliases {
+		aliases = append(aliases, Alias{Name: name, Expansion: expansion})
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].Name < aliases[j].Name
+	})
+	return aliases
+}
+
+// An aliasSpan locates an alias at `from` in a line, and its expansion at
+// `at` in the expanded line.
+type aliasSpan struct {
+	from, to int
+	at, end  int
+}
+
+// expand replaces the whitespace-separated fields of `line` which are
+// registered aliases with their expansion, and returns where they are. Fields
+// merely containing an alias are left alone.
+func (r *aliasRegistry) expand(line string) (string, []aliasSpan) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var expanded strings.Builder
+	var spans []aliasSpan
+	last := 0
+	for _, indices := range fieldFinder.FindAllStringIndex(line, -1) {
+		expansion, found := r.aliases[line[indices[0]:indices[1]]]
+		if !found {
+			continue
+		}
+		expanded.WriteString(line[last:indices[0]])
+		at := expanded.Len()
+		expanded.WriteString(expansion)
+		spans = append(spans, aliasSpan{from: indices[0], to: indices[1], at: at, end: expanded.Len()})
+		last = indices[1]
+	}
+	if last == 0 {
+		return line, nil
+	}
+	expanded.WriteString(line[last:])
+	return expanded.String(), spans
+}
+
+// unexpandSyntaxError makes the offset of `err`, if it is a *SyntaxError,
+// relative to `line` rather than to its expansion. An error found in the
+// expansion of an alias points at the alias.
+func unexpandSyntaxError(err error, line string, spans []aliasSpan) error {
+	var serr *SyntaxError
+	if len(spans) == 0 || !errors.As(err, &serr) {
+		return err
+	}
+	shift := 0
+	for _, span := range spans {
+		if serr.Offset < span.at {
+			break
+		}
+		if serr.Offset < span.end {
+			serr.Offset, serr.Token = span.from, line[span.from:span.to]
+			return err
+		}
+		shift = span.to - span.end
+	}
+	serr.Offset += shift
+	return err
+}
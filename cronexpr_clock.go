@@ -0,0 +1,191 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_clock.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"sync"
+	"time"
+)
+
+/******************************************************************************/
+
+// A Clock tells the time and makes timers. Tickers, timers and schedulers
+// driven by expressions take one, so that tests can substitute a FakeClock
+// for the RealClock.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) ClockTimer
+	After(d time.Duration) <-chan time.Time
+}
+
+// A ClockTimer is a timer made by a Clock, it behaves like time.Timer.
+type ClockTimer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+/******************************************************************************/
+
+// RealClock is the Clock of package time.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(d time.Duration) ClockTimer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+/******************************************************************************/
+
+// A FakeClock is a Clock whose time only changes when told to, firing its
+// timers accordingly. It is safe for concurrent use.
+type FakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	c      chan time.Time
+	at     time.Time
+	active bool
+}
+
+// NewFakeClock returns a new FakeClock telling `now` as the current time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) ClockTimer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	c.schedule(t, d)
+	return t
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Advance moves the time of `c` forward by `d`, firing the timers which
+// expire meanwhile.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set changes the time of `c` to `t`, possibly backward as when the wall clock
+// of a machine is adjusted, firing the timers which expire meanwhile.
+func (c *FakeClock) Set(t time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.set(t)
+}
+
+// Timers returns the number of timers of `c` which have yet to fire.
+func (c *FakeClock) Timers() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.timers)
+}
+
+func (c *FakeClock) set(t time.Time) {
+	c.now = t
+	timers := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(t) {
+			timers = append(timers, timer)
+			continue
+		}
+		timer.fire(t)
+	}
+	c.timers = timers
+}
+
+// schedule arms `t` to fire after `d`. The clock lock must be held.
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	t.at = c.now.Add(d)
+	t.active = true
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+	c.timers = append(c.timers, t)
+}
+
+// unschedule disarms `t` and reports whether it was armed. The clock lock
+// must be held.
+func (c *FakeClock) unschedule(t *fakeTimer) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	t.active = false
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	return t.clock.unschedule(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	active := t.clock.unschedule(t)
+	t.clock.schedule(t, d)
+	return active
+}
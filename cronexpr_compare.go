@@ -0,0 +1,128 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_compare.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"time"
+)
+
+/******************************************************************************/
+
+// Equal reports whether the expressions `a` and `b` match exactly the same
+// time instants, however differently they are written. For instance
+// `0 */6 * * *` and `0 0,6,12,18 * * *` are equal.
+//
+// See Contains for how the comparison is carried out, and for the
+// expressions which are never comparable, such as `@every 1h` anchored at the
+// top of an hour and `0 * * * *`: Equal returns false for them even though
+// they match the same time instants.
+func Equal(a, b *Expression) bool {
+	return Contains(a, b) && Contains(b, a)
+}
+
+// Contains reports whether every time instant matched by `b` is also matched
+// by `a`. An expression which never matches is contained in any other.
+//
+// The comparison is decided from the parsed fields, without enumerating time
+// instants: the days matched by the day-of-month and day-of-week fields,
+// including the `L`, `W` and `#` directives, are computed once per distinct
+// kind of month in the year range of `b`.
+//
+// Some expressions are never comparable, and Contains returns false for them:
+//   - expressions bound to time zones with different UTC offsets, whatever
+//     their names: `UTC` and `Etc/UTC` are comparable, see sameZone,
+//   - an expression bound to a time zone and one evaluated in that of the time
+//     values it is given,
+//   - `@every` expressions and other kinds of expressions, whatever their
+//     anchor: `@every 1h` anchored at the top of an hour does not contain
+//     `0 * * * *`, nor the reverse.
+//
+// The `@reboot` expression only contains itself.
+func Contains(a, b *Expression) bool {
+	if b.reboot || a.reboot {
+		return a.reboot && b.reboot
+	}
+	if b.every > 0 || a.every > 0 {
+		if a.every == 0 || b.every == 0 || b.every%a.every != 0 {
+			return false
+		}
+		return b.Anchor().Sub(a.Anchor())%a.every == 0
+	}
+
+	aDays, bDays := newDayMatcher(a), newDayMatcher(b)
+	fires := false
+	for _, year := range b.yearList {
+		for _, month := range b.monthList {
+			days := bDays.days(year, month)
+			if len(days) == 0 {
+				continue
+			}
+			fires = true
+			if !sortContains(a.yearList, year) || !sortContains(a.monthList, month) {
+				return false
+			}
+			if !sortContainsAll(aDays.days(year, month), days) {
+				return false
+			}
+		}
+	}
+	if !fires {
+		return true
+	}
+	if (a.timeZone == nil) != (b.timeZone == nil) {
+		return false
+	}
+	if a.timeZone != nil {
+		from := time.Date(b.yearList[0], time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(b.yearList[len(b.yearList)-1]+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if !sameZone(a.timeZone, b.timeZone, from, to) {
+			return false
+		}
+	}
+	return sortContainsAll(a.hourList, b.hourList) &&
+		sortContainsAll(a.minuteList, b.minuteList) &&
+		sortContainsAll(a.secondList, b.secondList)
+}
+
+/******************************************************************************/
+
+// sameZone reports whether the time zones `a` and `b` are the same within
+// [`from`, `to`): either the same location or, failing that, locations with
+// one and the same UTC offset throughout the range, whatever their names, such
+// as `UTC` and `Etc/UTC`. Equal, Contains, Overlaps and Diff all compare time
+// zones this way.
+func sameZone(a, b *time.Location, from, to time.Time) bool {
+	if a == b {
+		return true
+	}
+	aOffset, aFixed := fixedOffset(a, from, to)
+	bOffset, bFixed := fixedOffset(b, from, to)
+	return aFixed && bFixed && aOffset == bOffset
+}
+
+// fixedOffset returns the UTC offset of `loc` if it does not change within
+// [`from`, `to`).
+func fixedOffset(loc *time.Location, from, to time.Time) (int, bool) {
+	t := from.In(loc)
+	_, offset := t.Zone()
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || !end.Before(to) {
+			return offset, true
+		}
+		t = end.In(loc)
+		if _, next := t.Zone(); next != offset {
+			return 0, false
+		}
+	}
+}
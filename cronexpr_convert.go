@@ -0,0 +1,416 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_convert.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/******************************************************************************/
+
+// A Dialect is a syntax of schedules, see ParseDialect and Convert.
+type Dialect int
+
+const (
+	// Cron is the syntax of Vixie cron: five fields, from minute to day of
+	// week, numbered from 0 for Sunday.
+	Cron Dialect = iota
+	// Quartz is the syntax of the Quartz scheduler: six or seven fields, from
+	// second to year, with `?` in the day-of-month or the day-of-week field,
+	// which is numbered from 1 for Sunday.
+	Quartz
+	// Systemd is the syntax of systemd calendar events, see ParseSystemd.
+	Systemd
+	// EventBridge is the syntax of AWS EventBridge schedules: `cron(...)` with
+	// six fields, from minute to year, numbered as with Quartz, or
+	// `rate(...)`.
+	EventBridge
+)
+
+var dialectNames = map[Dialect]string{
+	Cron:        "cron",
+	Quartz:      "quartz",
+	Systemd:     "systemd",
+	EventBridge: "eventbridge",
+}
+
+func (d Dialect) String() string {
+	if name, found := dialectNames[d]; found {
+		return name
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// LookupDialect returns the dialect named `name`, as returned by
+// Dialect.String, regardless of case.
+func LookupDialect(name string) (Dialect, error) {
+	for d, dname := range dialectNames {
+		if strings.EqualFold(name, dname) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown dialect: \"%s\"", name)
+}
+
+// An Unsupported is a construct of an expression which cannot be expressed in
+// the dialect it is converted to.
+type Unsupported struct {
+	// Field is the name of the field of the construct, e.g. "day-of-week", or
+	// empty if the construct is not about a single field.
+	Field string
+	// Construct is the construct as written in the dialect the expression was
+	// parsed from, cron syntax unless it is Quartz or EventBridge, e.g. `5#3`
+	// in cron syntax for `6#3` in Quartz syntax.
+	Construct string
+	// Reason is what the construct stands for, e.g. "nth day of the week of
+	// the month".
+	Reason string
+}
+
+func (u Unsupported) String() string {
+	if u.Field == "" {
+		return fmt.Sprintf("'%s' (%s)", u.Construct, u.Reason)
+	}
+	return fmt.Sprintf("'%s' in %s field (%s)", u.Construct, u.Field, u.Reason)
+}
+
+// A ConvertError is returned by Convert for expressions which cannot be
+// expressed in the target dialect, and lists why.
+type ConvertError struct {
+	To          Dialect
+	Unsupported []Unsupported
+}
+
+func (e *ConvertError) Error() string {
+	parts := make([]string, len(e.Unsupported))
+	for i, u := range e.Unsupported {
+		parts[i] = u.String()
+	}
+	return fmt.Sprintf("cannot convert to %s: %s", e.To, strings.Join(parts, "; "))
+}
+
+/******************************************************************************/
+
+var (
+	quartzDowFinder = regexp.MustCompile(`(^|[,-])([1-7])`)
+	quartzFormat    = listFormat{rangeSep: "-"}
+)
+
+// ParseDialect returns a new Expression pointer from `line`, written in
+// dialect `d`. Quartz and EventBridge expressions are rewritten in cron syntax
+// before they are parsed, so that the offsets of a *SyntaxError refer to the
+// rewritten expression, while its token is the one of `line`.
+func ParseDialect(line string, d Dialect) (*Expression, error) {
+	switch d {
+	case Quartz:
+		fields := strings.Fields(line)
+		if len(fields) < 6 || len(fields) > 7 {
+			return nil, &SyntaxError{Offset: len(line), msg: "quartz expression must have 6 or 7 fields"}
+		}
+		if len(fields) == 6 {
+			fields = append(fields, "*")
+		}
+		return parseQuartz(fields, d)
+	case EventBridge:
+		s := strings.TrimSpace(line)
+		if strings.HasPrefix(s, "rate(") && strings.HasSuffix(s, ")") {
+			return parseRate(s[len("rate(") : len(s)-1])
+		}
+		if !strings.HasPrefix(s, "cron(") || !strings.HasSuffix(s, ")") {
+			return nil, &SyntaxError{msg: "eventbridge expression must be cron(...) or rate(...)"}
+		}
+		fields := strings.Fields(s[len("cron(") : len(s)-1])
+		if len(fields) != 6 {
+			return nil, &SyntaxError{Offset: len(line), msg: "eventbridge expression must have 6 fields"}
+		}
+		return parseQuartz(append([]string{"0"}, fields...), d)
+	case Systemd:
+		return ParseSystemd(line)
+	}
+	return Parse(line)
+}
+
+// parseQuartz parses the seven `fields` of a Quartz expression, from second
+// to year, once its days of week are renumbered. The expression remembers
+// `d`, so that Convert quotes its constructs as written.
+func parseQuartz(fields []string, d Dialect) (*Expression, error) {
+	dow := fields[5]
+	fields[5] = cronDaysOfWeek(dow)
+	expr, err := Parse(strings.Join(fields, " "))
+	if err != nil {
+		// Renumbering leaves the length of tokens untouched
+		var serr *SyntaxError
+		if errors.As(err, &serr) && serr.Field == dowDescriptor.name {
+			i := serr.Offset - len(strings.Join(fields[:5], " ")) - 1
+			if i >= 0 && i+len(serr.Token) <= len(dow) {
+				token := dow[i : i+len(serr.Token)]
+				serr.msg = strings.Replace(serr.msg, "'"+serr.Token+"'", "'"+token+"'", 1)
+				serr.Token = token
+			}
+		}
+		return nil, err
+	}
+	expr.dialect = d
+	return expr, nil
+}
+
+// cronDaysOfWeek renumbers the days of week of a Quartz day-of-week field from
+// 0 for Sunday, leaving names and the numbers following `#` or `/` untouched.
+// A bare `L` stands for the last day of the week, Saturday.
+func cronDaysOfWeek(field string) string {
+	if strings.EqualFold(field, "L") {
+		return "6"
+	}
+	return quartzDowFinder.ReplaceAllStringFunc(field, func(m string) string {
+		return m[:len(m)-1] + string(m[len(m)-1]-1)
+	})
+}
+
+// parseRate parses the `value unit` of an EventBridge `rate(...)` expression.
+func parseRate(rate string) (*Expression, error) {
+	units := map[string]time.Duration{
+		"minute": time.Minute, "minutes": time.Minute,
+		"hour": time.Hour, "hours": time.Hour,
+		"day": 24 * time.Hour, "days": 24 * time.Hour,
+	}
+	fields := strings.Fields(rate)
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[0])
+		if unit, found := units[fields[1]]; err == nil && n > 0 && found {
+			return Parse(fmt.Sprintf("@every %s", time.Duration(n)*unit))
+		}
+	}
+	return nil, &SyntaxError{Token: rate, msg: fmt.Sprintf("invalid rate %s", rate)}
+}
+
+/******************************************************************************/
+
+// Convert returns `expr` written in dialect `to`. If some constructs of
+// `expr` cannot be expressed in `to`, it returns a *ConvertError listing
+// them instead:
+//
+//   - Cron has neither seconds, years, nor `L`, `W`, `#` constructs.
+//   - Quartz and EventBridge cannot restrict both the day-of-month and the
+//     day-of-week fields, and only take `L`, `W` and `#` constructs on their
+//     own in a field. EventBridge has no seconds nor `LW` and `5L`.
+//   - Systemd has no `W` and `#` constructs, only takes `L` on its own, and
+//     matches days if both the day-of-month and the day-of-week fields match,
+//     rather than either.
+//
+// Time zones are only expressed in systemd calendar events, `@every`
+// expressions only in whole minutes with EventBridge, and `@reboot` only with
+// cron. Expressions which never fire are expressed in no dialect.
+func Convert(expr *Expression, to Dialect) (string, error) {
+	c := converter{expr: expr, to: to}
+	var s string
+	switch {
+	case expr.reboot:
+		s = c.reboot()
+	case expr.every > 0:
+		s = c.every()
+	case expr.neverFires:
+		c.reject("", expr.Normalized(), "never fires")
+	default:
+		c.check()
+		s = c.format()
+	}
+	if len(c.unsupported) > 0 {
+		return "", &ConvertError{To: to, Unsupported: c.unsupported}
+	}
+	return s, nil
+}
+
+// A converter writes an expression in a dialect, and collects the constructs
+// the dialect cannot express.
+type converter struct {
+	expr        *Expression
+	to          Dialect
+	unsupported []Unsupported
+}
+
+func (c *converter) reject(field, construct, reason string) {
+	c.unsupported = append(c.unsupported, Unsupported{Field: field, Construct: construct, Reason: reason})
+}
+
+// special checks a `L`, `W` or `#` construct, which is only supported by
+// `dialects`, and by them only if it is `alone` in its field.
+func (c *converter) special(field, construct, reason string, alone bool, dialects ...Dialect) {
+	for _, d := range dialects {
+		if d != c.to {
+			continue
+		}
+		if !alone {
+			c.reject(field, construct, reason+", along with other days")
+		}
+		return
+	}
+	c.reject(field, construct, reason)
+}
+
+// dayOfWeek returns the number of `dow`, from 0 for Sunday, in the dialect
+// the expression was parsed from.
+func (c *converter) dayOfWeek(dow int) int {
+	if c.expr.dialect == Quartz || c.expr.dialect == EventBridge {
+		return dow + 1
+	}
+	return dow
+}
+
+func (c *converter) reboot() string {
+	if c.to != Cron {
+		c.reject("", "@reboot", "run at startup")
+	}
+	return "@reboot"
+}
+
+func (c *converter) every() string {
+	every := c.expr.every
+	if c.to != EventBridge || every%time.Minute != 0 {
+		c.reject("", "@every "+every.String(), "fixed interval")
+		return ""
+	}
+	n, unit := int64(every/time.Minute), "minute"
+	if every%(24*time.Hour) == 0 {
+		n, unit = int64(every/(24*time.Hour)), "day"
+	} else if every%time.Hour == 0 {
+		n, unit = int64(every/time.Hour), "hour"
+	}
+	if n > 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("rate(%d %s)", n, unit)
+}
+
+func (c *converter) check() {
+	expr := c.expr
+	if expr.timeZone != nil && c.to != Systemd {
+		c.reject("", expr.zoneString(), "time zone")
+	}
+	if (len(expr.secondList) != 1 || expr.secondList[0] != 0) && (c.to == Cron || c.to == EventBridge) {
+		c.reject(secondDescriptor.name, cronFormat.format(expr.secondList, secondDescriptor, true), "seconds")
+	}
+	if !expr.allYears() && c.to == Cron {
+		c.reject(yearDescriptor.name, cronFormat.format(expr.yearList, yearDescriptor, true), "years")
+	}
+	if expr.daysOfMonthRestricted && expr.daysOfWeekRestricted && c.to != Cron {
+		construct := expr.normalizedDaysOfMonth(cronFormat) + " * " + expr.normalizedDaysOfWeek(cronFormat, nil)
+		c.reject("", construct, "days matching either the day-of-month or the day-of-week field")
+	}
+
+	if expr.daysOfMonthRestricted {
+		directives := len(expr.workdaysOfMonth)
+		if len(expr.daysOfMonth) > 0 {
+			directives += 1
+		}
+		if expr.lastDayOfMonth {
+			directives += 1
+		}
+		if expr.lastWorkdayOfMonth {
+			directives += 1
+		}
+		for _, day := range toList(expr.workdaysOfMonth) {
+			c.special(domDescriptor.name, fmt.Sprintf("%dW", day), "weekday nearest a day of the month", directives == 1, Quartz, EventBridge)
+		}
+		if expr.lastDayOfMonth {
+			c.special(domDescriptor.name, "L", "last day of the month", directives == 1, Quartz, EventBridge, Systemd)
+		}
+		if expr.lastWorkdayOfMonth {
+			c.special(domDescriptor.name, "LW", "last weekday of the month", directives == 1, Quartz)
+		}
+	}
+	if expr.daysOfWeekRestricted {
+		directives := len(expr.specificWeekDaysOfWeek) + len(expr.lastWeekDaysOfWeek)
+		if len(expr.daysOfWeek) > 0 {
+			directives += 1
+		}
+		for _, key := range toList(expr.specificWeekDaysOfWeek) {
+			c.special(dowDescriptor.name, fmt.Sprintf("%d#%d", c.dayOfWeek(key%7), key/7+1), "nth day of the week of the month", directives == 1, Quartz, EventBridge)
+		}
+		for _, dow := range toList(expr.lastWeekDaysOfWeek) {
+			c.special(dowDescriptor.name, fmt.Sprintf("%dL", c.dayOfWeek(dow)), "last day of the week of the month", directives == 1, Quartz)
+		}
+	}
+}
+
+func (c *converter) format() string {
+	expr := c.expr
+	switch c.to {
+	case Quartz:
+		dom, dow := expr.quartzDays()
+		fields := []string{
+			quartzFormat.format(expr.secondList, secondDescriptor, true),
+			quartzFormat.format(expr.minuteList, minuteDescriptor, true),
+			quartzFormat.format(expr.hourList, hourDescriptor, true),
+			dom,
+			quartzFormat.format(expr.monthList, monthDescriptor, true),
+			dow,
+		}
+		if !expr.allYears() {
+			fields = append(fields, quartzFormat.format(expr.yearList, yearDescriptor, true))
+		}
+		return strings.Join(fields, " ")
+	case EventBridge:
+		dom, dow := expr.quartzDays()
+		return fmt.Sprintf("cron(%s %s %s %s %s %s)",
+			quartzFormat.format(expr.minuteList, minuteDescriptor, true),
+			quartzFormat.format(expr.hourList, hourDescriptor, true),
+			dom,
+			quartzFormat.format(expr.monthList, monthDescriptor, true),
+			dow,
+			quartzFormat.format(expr.yearList, yearDescriptor, true))
+	case Systemd:
+		if expr.lastDayOfMonth {
+			// `~01` is the first day counting from the end of the month
+			return expr.formatSystemd("~01")
+		}
+		return expr.normalizedSystemd()
+	}
+	return strings.Join([]string{
+		cronFormat.format(expr.minuteList, minuteDescriptor, true),
+		cronFormat.format(expr.hourList, hourDescriptor, true),
+		expr.normalizedDaysOfMonth(cronFormat),
+		cronFormat.format(expr.monthList, monthDescriptor, true),
+		expr.normalizedDaysOfWeek(cronFormat, nil),
+	}, " ")
+}
+
+// quartzDays returns the day-of-month and day-of-week fields of `expr` in the
+// Quartz syntax, where one of them is `?`.
+func (expr *Expression) quartzDays() (string, string) {
+	if !expr.daysOfWeekRestricted {
+		return expr.normalizedDaysOfMonth(quartzFormat), "?"
+	}
+	// Days of week are numbered from 1 for Sunday
+	desc := dowDescriptor
+	desc.min, desc.max = 1, 7
+	var parts []string
+	if len(expr.daysOfWeek) > 0 {
+		days := toList(expr.daysOfWeek)
+		for i := range days {
+			days[i] += 1
+		}
+		parts = append(parts, quartzFormat.format(days, desc, false))
+	}
+	for _, key := range toList(expr.specificWeekDaysOfWeek) {
+		parts = append(parts, fmt.Sprintf("%d#%d", key%7+1, key/7+1))
+	}
+	for _, dow := range toList(expr.lastWeekDaysOfWeek) {
+		parts = append(parts, fmt.Sprintf("%dL", dow+1))
+	}
+	return "?", strings.Join(parts, ",")
+}
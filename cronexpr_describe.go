@@ -0,0 +1,236 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_describe.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"fmt"
+	"strings"
+)
+
+/******************************************************************************/
+
+var (
+	monthNames = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	dayNames   = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	ordinals   = []string{"first", "second", "third", "fourth", "fifth"}
+)
+
+// maxListedTimes is the largest number of times of day Describe lists one by
+// one, e.g. "at 09:30 and 17:30".
+const maxListedTimes = 6
+
+/******************************************************************************/
+
+// Describe returns a description of `expr` in English, e.g. "every 15 minutes
+// past hours 9 through 17, on Monday through Friday" for `*/15 9-17 * * 1-5`,
+// or "never" if `expr` does not match any time instant.
+func (expr *Expression) Describe() string {
+	if expr.reboot {
+		return "at startup"
+	}
+	if expr.every > 0 {
+		return "every " + expr.every.String()
+	}
+	if expr.neverFires {
+		return "never"
+	}
+
+	var clauses []string
+	days := expr.describeDays()
+	if len(expr.secondList)*len(expr.minuteList)*len(expr.hourList) <= maxListedTimes {
+		clauses = append(clauses, expr.describeTimes())
+		if days == "" {
+			days = "every day"
+		}
+	} else {
+		clauses = append(clauses, expr.describeTimeFields())
+	}
+	if days != "" {
+		clauses = append(clauses, days)
+	}
+	if len(expr.monthList) < len(monthDescriptor.defaultList) {
+		clauses = append(clauses, "in "+describeList(expr.monthList, "", func(v int) string {
+			return monthNames[v]
+		}))
+	}
+	if !expr.allYears() {
+		clauses = append(clauses, "in "+describeList(expr.yearList, "", nil))
+	}
+	description := strings.Join(clauses, ", ")
+	if expr.systemd && expr.timeZone != nil {
+		description += fmt.Sprintf(" (%s)", strings.ToUpper(expr.timeZone.String()))
+	}
+	return description
+}
+
+// describeTimes lists the times of day of `expr`.
+func (expr *Expression) describeTimes() string {
+	withSeconds := len(expr.secondList) > 1 || expr.secondList[0] != 0
+	var times []string
+	for _, h := range expr.hourList {
+		for _, m := range expr.minuteList {
+			for _, s := range expr.secondList {
+				if withSeconds {
+					times = append(times, fmt.Sprintf("%02d:%02d:%02d", h, m, s))
+				} else {
+					times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+				}
+			}
+		}
+	}
+	return "at " + joinEnglish(times)
+}
+
+// describeTimeFields describes the second, minute and hour fields of `expr`
+// one after the other.
+func (expr *Expression) describeTimeFields() string {
+	allSeconds := len(expr.secondList) == len(secondDescriptor.defaultList)
+	allMinutes := len(expr.minuteList) == len(minuteDescriptor.defaultList)
+	allHours := len(expr.hourList) == len(hourDescriptor.defaultList)
+
+	// The finest restricted field comes first, e.g. "at minute 0 past every
+	// hour"
+	var phrases []string
+	var next string
+	if len(expr.secondList) > 1 || expr.secondList[0] != 0 {
+		phrases = append(phrases, describeField(expr.secondList, secondDescriptor, "second"))
+		next = "minute"
+		if !allMinutes {
+			phrases = append(phrases, "past "+describeField(expr.minuteList, minuteDescriptor, "minute"))
+			next = "hour"
+		}
+	} else {
+		phrases = append(phrases, describeField(expr.minuteList, minuteDescriptor, "minute"))
+		next = "hour"
+	}
+	if !strings.HasPrefix(phrases[0], "every ") {
+		phrases[0] = "at " + phrases[0]
+		if allHours {
+			phrases = append(phrases, "past every "+next)
+		}
+	}
+	if !allHours {
+		preposition := "past "
+		if allMinutes && (allSeconds || len(phrases) == 1) {
+			preposition = "during "
+		}
+		phrases = append(phrases, preposition+describeField(expr.hourList, hourDescriptor, "hour"))
+	}
+	return strings.Join(phrases, " ")
+}
+
+// describeDays describes the day-of-month and day-of-week fields of `expr`,
+// or returns an empty string if neither is restricted.
+func (expr *Expression) describeDays() string {
+	var days []string
+	if expr.daysOfMonthRestricted {
+		if len(expr.daysOfMonth) > 0 {
+			days = append(days, "on "+describeList(toList(expr.daysOfMonth), "day", nil)+" of the month")
+		}
+		for _, day := range toList(expr.workdaysOfMonth) {
+			days = append(days, fmt.Sprintf("on the weekday nearest day %d of the month", day))
+		}
+		if expr.lastDayOfMonth {
+			days = append(days, "on the last day of the month")
+		}
+		if expr.lastWorkdayOfMonth {
+			days = append(days, "on the last weekday of the month")
+		}
+	}
+	if expr.daysOfWeekRestricted {
+		if len(expr.daysOfWeek) > 0 {
+			// Number days from Monday so that ranges do not wrap around
+			fromMonday := make([]int, 0, len(expr.daysOfWeek))
+			for dow := 1; dow <= 7; dow++ {
+				if expr.daysOfWeek[dow%7] {
+					fromMonday = append(fromMonday, dow)
+				}
+			}
+			days = append(days, "on "+describeList(fromMonday, "", func(v int) string {
+				return dayNames[v%7]
+			}))
+		}
+		for _, key := range toList(expr.specificWeekDaysOfWeek) {
+			days = append(days, fmt.Sprintf("on the %s %s of the month", ordinals[key/7], dayNames[key%7]))
+		}
+		for _, dow := range toList(expr.lastWeekDaysOfWeek) {
+			days = append(days, fmt.Sprintf("on the last %s of the month", dayNames[dow]))
+		}
+	}
+	return strings.Join(days, " or ")
+}
+
+/******************************************************************************/
+
+// describeField describes the values of a time field, e.g. "every minute",
+// "every 15 minutes" or "minutes 0 through 5 and 30".
+func describeField(values []int, desc fieldDescriptor, unit string) string {
+	if len(values) == desc.max-desc.min+1 {
+		return "every " + unit
+	}
+	if len(values) >= 3 {
+		step := values[1] - values[0]
+		regular := step > 1 && values[len(values)-1]+step > desc.max
+		for i := 2; regular && i < len(values); i++ {
+			regular = values[i]-values[i-1] == step
+		}
+		if regular && values[0] == desc.min {
+			return fmt.Sprintf("every %d %ss", step, unit)
+		} else if regular {
+			return fmt.Sprintf("every %d %ss from %s %d", step, unit, unit, values[0])
+		}
+	}
+	return describeList(values, unit, nil)
+}
+
+// describeList describes a list of values, with consecutive values as
+// ranges, e.g. "days 1 through 5 and 15". The values are preceded by `unit`,
+// if not empty, and written by `name`, if not nil.
+func describeList(values []int, unit string, name func(v int) string) string {
+	if name == nil {
+		name = func(v int) string {
+			return fmt.Sprint(v)
+		}
+	}
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, name(values[i])+" through "+name(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, name(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	list := joinEnglish(parts)
+	if unit == "" {
+		return list
+	}
+	if len(values) > 1 {
+		unit += "s"
+	}
+	return unit + " " + list
+}
+
+// joinEnglish joins `parts` as in "a, b and c".
+func joinEnglish(parts []string) string {
+	if len(parts) <= 1 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
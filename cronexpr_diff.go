@@ -0,0 +1,86 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_diff.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"time"
+)
+
+/******************************************************************************/
+
+// A DiffSide tells which of the two expressions compared by Diff matches a
+// time instant.
+type DiffSide int
+
+const (
+	// OnlyOld means the time instant is matched by the old expression only.
+	OnlyOld DiffSide = iota
+	// OnlyNew means the time instant is matched by the new expression only.
+	OnlyNew
+)
+
+func (side DiffSide) String() string {
+	if side == OnlyOld {
+		return "old"
+	}
+	return "new"
+}
+
+/******************************************************************************/
+
+// Diff compares the time instants matched by `oldExpr` and `newExpr` within
+// [`from`, `to`). It calls `fn`, in chronological ascending order, for each
+// time instant matched by only one of them, and returns the number of time
+// instants matched by both.
+//
+// The matches of both expressions are walked through side by side with Next,
+// so that neither list is ever held in memory. If `fn` returns false, Diff
+// stops and returns the number of common time instants found so far.
+//
+// The `time.Location` of the time instants passed to `fn` is the same as that
+// of `from`.
+func Diff(oldExpr, newExpr *Expression, from, to time.Time, fn func(t time.Time, side DiffSide) bool) int {
+	inRange := func(t time.Time) bool {
+		return !t.IsZero() && t.Before(to)
+	}
+	a, b := firstFrom(oldExpr, from), firstFrom(newExpr, from)
+	common := 0
+	for inRange(a) || inRange(b) {
+		switch {
+		case inRange(a) && inRange(b) && a.Equal(b):
+			common += 1
+			a, b = oldExpr.Next(a), newExpr.Next(b)
+		case inRange(a) && (!inRange(b) || a.Before(b)):
+			if !fn(a, OnlyOld) {
+				return common
+			}
+			a = oldExpr.Next(a)
+		default:
+			if !fn(b, OnlyNew) {
+				return common
+			}
+			b = newExpr.Next(b)
+		}
+	}
+	return common
+}
+
+// firstFrom returns the first time instant matched by `expr` at or after
+// `from`, or the zero value of time.Time if none.
+func firstFrom(expr *Expression, from time.Time) time.Time {
+	t := expr.Next(from.Add(-time.Second))
+	for !t.IsZero() && t.Before(from) {
+		t = expr.Next(t)
+	}
+	return t
+}
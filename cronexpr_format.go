@@ -0,0 +1,250 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_format.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/******************************************************************************/
+
+var dowNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+var (
+	cronFormat    = listFormat{rangeSep: "-", wildcardStep: true}
+	systemdFormat = listFormat{rangeSep: "..", width: 2}
+)
+
+/******************************************************************************/
+
+// Normalized returns the canonical form of `expr`, in the syntax it was parsed
+// from: aliases are expanded, names become numbers, and lists, ranges and
+// steps are rewritten in their shortest usual form.
+//
+// Cron expressions are normalized to five fields, or to seven fields if they
+// restrict seconds or years, e.g. `*/15 9-17 * * 1-5`. Systemd calendar events
+// are normalized like `systemd-analyze calendar` does, e.g.
+// `Mon..Fri *-*-* 09..17:00/15:00`.
+//
+// Expressions with a field which matches no value at all, e.g. `5-2`, have no
+// canonical form: they are returned as written, once aliases are expanded.
+func (expr *Expression) Normalized() string {
+	if expr.reboot {
+		return "@reboot"
+	}
+	if expr.every > 0 {
+		return "@every " + expr.every.String()
+	}
+	if expr.hasEmptyField() {
+		return strings.Join(strings.Fields(expr.expression), " ")
+	}
+	if expr.systemd {
+		return expr.normalizedSystemd()
+	}
+	return expr.normalizedCron()
+}
+
+func (expr *Expression) normalizedCron() string {
+	fields := []string{
+		cronFormat.format(expr.minuteList, minuteDescriptor, true),
+		cronFormat.format(expr.hourList, hourDescriptor, true),
+		expr.normalizedDaysOfMonth(cronFormat),
+		cronFormat.format(expr.monthList, monthDescriptor, true),
+		expr.normalizedDaysOfWeek(cronFormat, nil),
+	}
+	if len(expr.secondList) == 1 && expr.secondList[0] == 0 && expr.allYears() {
+		return strings.Join(fields, " ")
+	}
+	fields = append([]string{cronFormat.format(expr.secondList, secondDescriptor, true)}, fields...)
+	return strings.Join(append(fields, cronFormat.format(expr.yearList, yearDescriptor, true)), " ")
+}
+
+func (expr *Expression) normalizedSystemd() string {
+	return expr.formatSystemd("-" + expr.normalizedDaysOfMonth(systemdFormat))
+}
+
+// hasEmptyField reports whether a field of `expr` matches no value, e.g. `5-2`.
+func (expr *Expression) hasEmptyField() bool {
+	for _, list := range [][]int{expr.secondList, expr.minuteList, expr.hourList, expr.monthList, expr.yearList} {
+		if len(list) == 0 {
+			return true
+		}
+	}
+	if expr.daysOfMonthRestricted && len(expr.daysOfMonth) == 0 && len(expr.workdaysOfMonth) == 0 && !expr.lastDayOfMonth && !expr.lastWorkdayOfMonth {
+		return true
+	}
+	return expr.daysOfWeekRestricted && len(expr.daysOfWeek) == 0 && len(expr.specificWeekDaysOfWeek) == 0 && len(expr.lastWeekDaysOfWeek) == 0
+}
+
+// formatSystemd writes `expr` as a systemd calendar event whose day of month
+// is `days`, along with its separator from the month.
+func (expr *Expression) formatSystemd(days string) string {
+	years := listFormat{rangeSep: "..", width: 4}
+	var s strings.Builder
+	if expr.daysOfWeekRestricted {
+		s.WriteString(expr.normalizedDaysOfWeek(systemdFormat, dowNames))
+		s.WriteString(" ")
+	}
+	fmt.Fprintf(&s, "%s-%s%s %s:%s:%s",
+		years.format(expr.yearList, yearDescriptor, true),
+		systemdFormat.format(expr.monthList, monthDescriptor, true),
+		days,
+		systemdFormat.format(expr.hourList, hourDescriptor, true),
+		systemdFormat.format(expr.minuteList, minuteDescriptor, true),
+		systemdFormat.format(expr.secondList, secondDescriptor, true))
+	if expr.timeZone != nil {
+		s.WriteString(" ")
+		s.WriteString(expr.zoneString())
+	}
+	return s.String()
+}
+
+// zoneString returns the name of the time zone of `expr`. Calendar events are
+// lowercased when parsed, so abbreviations such as `UTC` are restored.
+func (expr *Expression) zoneString() string {
+	zone := expr.timeZone.String()
+	if !strings.Contains(zone, "/") {
+		zone = strings.ToUpper(zone)
+	}
+	return zone
+}
+
+// allYears reports whether the year field of `expr` is unrestricted.
+func (expr *Expression) allYears() bool {
+	return len(expr.yearList) == yearDescriptor.max-yearDescriptor.min+1
+}
+
+func (expr *Expression) normalizedDaysOfMonth(f listFormat) string {
+	if !expr.daysOfMonthRestricted {
+		return "*"
+	}
+	// A restricted field is never written as `*`, which would change how
+	// it combines with a restricted day-of-week field
+	var parts []string
+	if len(expr.daysOfMonth) > 0 {
+		parts = append(parts, f.format(toList(expr.daysOfMonth), domDescriptor, false))
+	}
+	for _, day := range toList(expr.workdaysOfMonth) {
+		parts = append(parts, f.value(day)+"W")
+	}
+	if expr.lastDayOfMonth {
+		parts = append(parts, "L")
+	}
+	if expr.lastWorkdayOfMonth {
+		parts = append(parts, "LW")
+	}
+	return strings.Join(parts, ",")
+}
+
+// normalizedDaysOfWeek formats the day-of-week field, using `names` if not
+// nil, in which case weeks start on Monday.
+func (expr *Expression) normalizedDaysOfWeek(f listFormat, names []string) string {
+	if !expr.daysOfWeekRestricted {
+		return "*"
+	}
+	days := toList(expr.daysOfWeek)
+	name := func(dow int) string {
+		if names == nil {
+			return fmt.Sprint(dow)
+		}
+		return names[dow]
+	}
+	var parts []string
+	if len(days) > 0 {
+		if names == nil {
+			parts = append(parts, f.format(days, dowDescriptor, false))
+		} else {
+			// Number days from Monday so that ranges do not wrap around
+			fromMonday := make([]int, len(days))
+			for i, dow := range days {
+				fromMonday[i] = (dow + 6) % 7
+			}
+			sort.Ints(fromMonday)
+			f.name = func(v int) string {
+				return names[(v+1)%7]
+			}
+			parts = append(parts, f.format(fromMonday, dowDescriptor, false))
+		}
+	}
+	for _, key := range toList(expr.specificWeekDaysOfWeek) {
+		parts = append(parts, fmt.Sprintf("%s#%d", name(key%7), key/7+1))
+	}
+	for _, dow := range toList(expr.lastWeekDaysOfWeek) {
+		parts = append(parts, name(dow)+"L")
+	}
+	return strings.Join(parts, ",")
+}
+
+/******************************************************************************/
+
+// A listFormat writes sorted field values as a list of values, ranges and
+// steps.
+type listFormat struct {
+	rangeSep string
+	// width is the minimum number of digits of values
+	width int
+	// wildcardStep writes steps starting at the minimum of the field as
+	// `*/step`
+	wildcardStep bool
+	// name, if not nil, writes values instead of digits
+	name func(v int) string
+}
+
+func (f listFormat) value(v int) string {
+	if f.name != nil {
+		return f.name(v)
+	}
+	return fmt.Sprintf("%0*d", f.width, v)
+}
+
+// format writes `values`, as `*` if they cover the whole field and `star` is
+// true.
+func (f listFormat) format(values []int, desc fieldDescriptor, star bool) string {
+	if star && len(values) == desc.max-desc.min+1 {
+		return "*"
+	}
+	// `first/step` up to the end of the field, unless a short list reads
+	// better, e.g. `1,3,5`
+	if (len(values) >= 4 || len(values) == 3 && values[0] == desc.min) && f.name == nil {
+		step := values[1] - values[0]
+		regular := step > 1 && values[len(values)-1]+step > desc.max
+		for i := 2; regular && i < len(values); i++ {
+			regular = values[i]-values[i-1] == step
+		}
+		if regular {
+			if f.wildcardStep && values[0] == desc.min {
+				return fmt.Sprintf("*/%d", step)
+			}
+			return fmt.Sprintf("%s/%d", f.value(values[0]), step)
+		}
+	}
+	// Consecutive values, as ranges of three values or more
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, f.value(values[i])+f.rangeSep+f.value(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, f.value(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
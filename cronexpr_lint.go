@@ -0,0 +1,299 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_lint.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+/******************************************************************************/
+
+// A WarningKind identifies the kind of suspicious construct reported by Lint.
+type WarningKind int
+
+const (
+	// NeverFires means the expression does not match any time instant.
+	NeverFires WarningKind = iota
+	// SomeYearsOnly means the expression matches in some years of its year
+	// field but not in others, e.g. `0 0 29 2 *`.
+	SomeYearsOnly
+	// DayOfMonthAndWeek means both the day-of-month and day-of-week fields
+	// are restricted, so that a day matches if either field matches.
+	DayOfMonthAndWeek
+	// UnevenStep means a step does not divide the range of its field, so that
+	// the gap differs when the field wraps around, e.g. `*/7` minutes.
+	UnevenStep
+	// StepExceedsRange means a step is so large that only the first value of
+	// its range matches, e.g. `10-20/15`.
+	StepExceedsRange
+	// DuplicateEntry means a value is listed more than once in a field,
+	// e.g. `1-5,3`.
+	DuplicateEntry
+	// DaylightSavingGap means some matching wall clock time does not exist on
+	// some day because of daylight saving.
+	DaylightSavingGap
+)
+
+var warningKindNames = map[WarningKind]string{
+	NeverFires:        "never-fires",
+	SomeYearsOnly:     "some-years-only",
+	DayOfMonthAndWeek: "day-of-month-and-week",
+	UnevenStep:        "uneven-step",
+	StepExceedsRange:  "step-exceeds-range",
+	DuplicateEntry:    "duplicate-entry",
+	DaylightSavingGap: "daylight-saving-gap",
+}
+
+func (kind WarningKind) String() string {
+	if name, found := warningKindNames[kind]; found {
+		return name
+	}
+	return fmt.Sprintf("WarningKind(%d)", int(kind))
+}
+
+// A Warning is a suspicious construct found by Lint.
+type Warning struct {
+	Kind WarningKind
+	// Field is the name of the offending field, if any, e.g. "minute".
+	Field   string
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Message
+}
+
+/******************************************************************************/
+
+// Lint returns warnings about constructs of `expr` which are valid but likely
+// not what was meant: schedules which never fire or only fire in some years,
+// restricted day-of-month and day-of-week fields, uneven or oversized steps,
+// duplicate list entries, and, if `loc` is not nil, matching times which do
+// not exist on some days in `loc` because of daylight saving.
+//
+// Steps and list entries are only checked for cron expressions, as returned by
+// Parse.
+func Lint(expr *Expression, loc *time.Location) []Warning {
+	var warnings []Warning
+	if expr.reboot || expr.every > 0 {
+		return warnings
+	}
+
+	for _, field := range expr.cronFields() {
+		warnings = append(warnings, lintField(field.text, field.desc)...)
+	}
+
+	if expr.daysOfMonthRestricted && expr.daysOfWeekRestricted {
+		warnings = append(warnings, Warning{
+			Kind:    DayOfMonthAndWeek,
+			Message: "both day-of-month and day-of-week fields are restricted: a day matches if either field matches",
+		})
+	}
+
+	if expr.neverFires {
+		return append(warnings, Warning{
+			Kind:    NeverFires,
+			Message: ErrNeverFires.Error(),
+		})
+	}
+	years := expr.firingYears()
+	if len(years) < len(expr.yearList) {
+		missing := 0
+		for _, y := range expr.yearList {
+			if !sortContains(years, y) {
+				missing = y
+				break
+			}
+		}
+		warnings = append(warnings, Warning{
+			Kind:    SomeYearsOnly,
+			Field:   yearDescriptor.name,
+			Message: fmt.Sprintf("expression fires in only %d of %d years, e.g. not in %d", len(years), len(expr.yearList), missing),
+		})
+	}
+
+	if loc != nil {
+		if warning, found := expr.lintDaylightSaving(loc); found {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+/******************************************************************************/
+
+func lintField(s string, desc fieldDescriptor) []Warning {
+	var warnings []Warning
+	directives, err := genericFieldParse(s, desc)
+	if err != nil {
+		return warnings
+	}
+
+	cyclic := desc.name != domDescriptor.name && desc.name != yearDescriptor.name
+	seen := make(map[int]bool)
+	duplicate := false
+	for _, directive := range directives {
+		sdirective := s[directive.sbeg:directive.send]
+		switch directive.kind {
+		case one:
+			duplicate = duplicate || seen[directive.first]
+			seen[directive.first] = true
+		case span:
+			last := directive.last
+			// Day-of-week spans that end in 7 (Sunday) end in 0 once parsed
+			dow := desc.name == dowDescriptor.name
+			if dow && last == 0 {
+				last = 7
+			}
+			values := make(map[int]bool)
+			for v := directive.first; v <= last; v += directive.step {
+				if dow {
+					values[v%7] = true
+				} else {
+					values[v] = true
+				}
+			}
+			for v := range values {
+				duplicate = duplicate || seen[v]
+				seen[v] = true
+			}
+			if directive.step <= 1 {
+				continue
+			}
+			if directive.first+directive.step > last {
+				warnings = append(warnings, Warning{
+					Kind:    StepExceedsRange,
+					Field:   desc.name,
+					Message: fmt.Sprintf("step in %s field: '%s' only matches %d", desc.name, sdirective, directive.first),
+				})
+				continue
+			}
+			// `*/7` or `5/7`, which wrap around at the end of the field
+			if cyclic && !strings.ContainsAny(strings.SplitN(sdirective, "/", 2)[0], "-.") {
+				period := desc.max - desc.min + 1
+				lastValue := directive.first + (last-directive.first)/directive.step*directive.step
+				if directive.first+period-lastValue != directive.step {
+					warnings = append(warnings, Warning{
+						Kind:    UnevenStep,
+						Field:   desc.name,
+						Message: fmt.Sprintf("step in %s field: '%s' is uneven, %d does not divide %d", desc.name, sdirective, directive.step, period),
+					})
+				}
+			}
+		}
+	}
+	if duplicate {
+		warnings = append(warnings, Warning{
+			Kind:    DuplicateEntry,
+			Field:   desc.name,
+			Message: fmt.Sprintf("duplicate entries in %s field: '%s'", desc.name, s),
+		})
+	}
+	return warnings
+}
+
+func (expr *Expression) lintDaylightSaving(loc *time.Location) (Warning, bool) {
+	times := expr.timesOfDay()
+	days := newDayMatcher(expr)
+	for _, y := range expr.yearList {
+		for _, m := range expr.monthList {
+			begin := time.Date(y, time.Month(m), 1, 0, 0, 0, 0, loc)
+			end := begin.AddDate(0, 1, 0)
+			_, beginOffset := begin.Zone()
+			_, endOffset := end.Zone()
+			// Only clocks moving forward skip wall clock times
+			if endOffset <= beginOffset {
+				continue
+			}
+			// Find the first second of the new offset
+			lo, hi := begin.Unix(), end.Unix()
+			for hi-lo > 1 {
+				mid := lo + (hi-lo)/2
+				if _, offset := time.Unix(mid, 0).In(loc).Zone(); offset == beginOffset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			transition := time.Unix(hi, 0).In(loc)
+			if !sortContains(days.days(y, m), transition.Day()) {
+				continue
+			}
+			gapEnd := secondOfDay(transition, false)
+			gapBegin := gapEnd - (endOffset - beginOffset)
+			i := sort.SearchInts(times, gapBegin)
+			if i < len(times) && times[i] < gapEnd {
+				return Warning{
+					Kind: DaylightSavingGap,
+					Message: fmt.Sprintf("%02d:%02d:%02d does not exist on %s in %s because of daylight saving",
+						times[i]/3600, times[i]/60%60, times[i]%60, transition.Format("2006-01-02"), loc),
+				}, true
+			}
+		}
+	}
+	return Warning{}, false
+}
+
+/******************************************************************************/
+
+type exprField struct {
+	desc fieldDescriptor
+	text string
+}
+
+// cronFields returns the fields of a cron expression as written, along with
+// their descriptor.
+func (expr *Expression) cronFields() []exprField {
+	if expr.systemd {
+		return nil
+	}
+	indices := fieldFinder.FindAllStringIndex(expr.expression, -1)
+	fieldCount := len(indices)
+	if fieldCount < 5 {
+		return nil
+	}
+	// ignore fields beyond 7th
+	if fieldCount > 7 {
+		fieldCount = 7
+	}
+	descs := []fieldDescriptor{minuteDescriptor, hourDescriptor, domDescriptor, monthDescriptor, dowDescriptor}
+	if fieldCount == 7 {
+		descs = append([]fieldDescriptor{secondDescriptor}, descs...)
+	}
+	if fieldCount > 5 {
+		descs = append(descs, yearDescriptor)
+	}
+	fields := make([]exprField, len(descs))
+	for i, desc := range descs {
+		fields[i] = exprField{desc: desc, text: expr.expression[indices[i][0]:indices[i][1]]}
+	}
+	return fields
+}
+
+// firingYears returns the years of the year field in which `expr` matches.
+func (expr *Expression) firingYears() []int {
+	var years []int
+	days := newDayMatcher(expr)
+	for _, y := range expr.yearList {
+		for _, m := range expr.monthList {
+			if len(days.days(y, m)) > 0 {
+				years = append(years, y)
+				break
+			}
+		}
+	}
+	return years
+}
@@ -118,6 +118,47 @@ func (expr *Expression) calculateActualDaysOfMonth(year, month int) []int {
 	return toList(actualDaysOfMonthMap)
 }
 
+// The days of a month matched by an expression only depend on the number of
+// days in that month and on the day of week of its first day, so there are at
+// most 28 distinct outcomes of calculateActualDaysOfMonth per expression.
+type monthShape struct {
+	lastDay      int
+	firstWeekday time.Weekday
+}
+
+func shapeOfMonth(year, month int) monthShape {
+	firstDayOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return monthShape{
+		lastDay:      firstDayOfMonth.AddDate(0, 1, -1).Day(),
+		firstWeekday: firstDayOfMonth.Weekday(),
+	}
+}
+
+// A dayMatcher memoizes calculateActualDaysOfMonth by month shape. It is meant
+// for analyses scanning many months, it is not safe for concurrent use.
+type dayMatcher struct {
+	expr  *Expression
+	cache map[monthShape][]int
+}
+
+func newDayMatcher(expr *Expression) *dayMatcher {
+	return &dayMatcher{expr: expr, cache: make(map[monthShape][]int)}
+}
+
+// days returns the days of the given month matched by the day-of-month and
+// day-of-week fields, regardless of the month and year fields.
+func (m *dayMatcher) days(year, month int) []int {
+	shape := shapeOfMonth(year, month)
+	days, found := m.cache[shape]
+	if !found {
+		days = m.expr.calculateActualDaysOfMonth(year, month)
+		m.cache[shape] = days
+	}
+	return days
+}
+
+/******************************************************************************/
+
 func workdayOfMonth(targetDom, lastDom time.Time) int {
 	// If saturday, then friday
 	// If sunday, then monday
@@ -144,6 +185,17 @@ func sortContains(a []int, x int) bool {
 	return i < len(a) && a[i] == x
 }
 
+// sortContainsAll reports whether all values of the sorted list `b` are in the
+// sorted list `a`.
+func sortContainsAll(a, b []int) bool {
+	for _, x := range b {
+		if !sortContains(a, x) {
+			return false
+		}
+	}
+	return true
+}
+
 func timeZoneInDay(t time.Time) bool {
 	if t.Location() == time.UTC {
 		return false
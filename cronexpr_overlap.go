@@ -0,0 +1,238 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_overlap.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"sort"
+	"time"
+)
+
+/******************************************************************************/
+
+// An Overlap is a time instant matched by two or more expressions.
+type Overlap struct {
+	Time time.Time
+	// Names are the names of the matching expressions, in ascending order.
+	Names []string
+}
+
+/******************************************************************************/
+
+// Overlaps returns the time instants within [`from`, `to`) at which two or
+// more of the named expressions `exprs` match, in chronological ascending
+// order.
+//
+// Every overlap is held in memory: with frequent expressions or long ranges,
+// use ForEachOverlap instead.
+func Overlaps(exprs map[string]*Expression, from, to time.Time) []Overlap {
+	overlaps := []Overlap{}
+	ForEachOverlap(exprs, from, to, func(overlap Overlap) bool {
+		overlaps = append(overlaps, overlap)
+		return true
+	})
+	return overlaps
+}
+
+// ForEachOverlap calls `fn`, in chronological ascending order, for each time
+// instant within [`from`, `to`) at which two or more of the named expressions
+// `exprs` match. If `fn` returns false, ForEachOverlap stops.
+//
+// The range is walked through one day at a time, so that only the overlaps of
+// a day are ever held in memory. The matches common to two cron expressions
+// are derived from the intersection of their fields, so that only the time
+// instants shared by both are ever computed. Only `@every` expressions and
+// expressions bound to time zones whose UTC offsets differ within the day
+// require walking through the matches of one of the pair with Next.
+// `@reboot` expressions never overlap.
+//
+// The `time.Location` of the time instants passed to `fn` is the same as that
+// of `from`.
+func ForEachOverlap(exprs map[string]*Expression, from, to time.Time, fn func(overlap Overlap) bool) {
+	names := make([]string, 0, len(exprs))
+	for name, expr := range exprs {
+		if !expr.reboot {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	loc := from.Location()
+	for start := from; start.Before(to); {
+		y, m, d := start.In(loc).Date()
+		end := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		if end.After(to) {
+			end = to
+		}
+		for _, overlap := range overlapsWithin(exprs, names, start, end) {
+			if !fn(overlap) {
+				return
+			}
+		}
+		start = end
+	}
+}
+
+// overlapsWithin returns the overlaps of the expressions `names` of `exprs`
+// within [`from`, `to`), in chronological ascending order.
+func overlapsWithin(exprs map[string]*Expression, names []string, from, to time.Time) []Overlap {
+	matches := make(map[time.Time]map[string]bool)
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			a, b := names[i], names[j]
+			forEachCommon(exprs[a], exprs[b], from, to, func(t time.Time) {
+				t = t.In(from.Location())
+				if matches[t] == nil {
+					matches[t] = make(map[string]bool)
+				}
+				matches[t][a] = true
+				matches[t][b] = true
+			})
+		}
+	}
+
+	overlaps := make([]Overlap, 0, len(matches))
+	for t, set := range matches {
+		overlap := Overlap{Time: t, Names: make([]string, 0, len(set))}
+		for name := range set {
+			overlap.Names = append(overlap.Names, name)
+		}
+		sort.Strings(overlap.Names)
+		overlaps = append(overlaps, overlap)
+	}
+	sort.Slice(overlaps, func(i, j int) bool {
+		return overlaps[i].Time.Before(overlaps[j].Time)
+	})
+	return overlaps
+}
+
+/******************************************************************************/
+
+// forEachCommon calls `fn` for each time instant within [`from`, `to`)
+// matched by both `a` and `b`.
+func forEachCommon(a, b *Expression, from, to time.Time, fn func(time.Time)) {
+	if a.every == 0 && b.every == 0 && sameZone(a.location(from), b.location(from), from, to) {
+		forEachCommonField(a, b, from, to, fn)
+		return
+	}
+	// Walk through the sparsest of the pair, `@every` expressions with
+	// the longest interval first
+	if b.every > a.every {
+		a, b = b, a
+	}
+	forEachBetween(a, from, to, func(t time.Time) {
+		if b.matches(t) {
+			fn(t)
+		}
+	})
+}
+
+// location returns the time zone in which `expr` is evaluated against `t`.
+func (expr *Expression) location(t time.Time) *time.Location {
+	if expr.timeZone != nil {
+		return expr.timeZone
+	}
+	return t.Location()
+}
+
+// forEachCommonField calls `fn` for each time instant within [`from`, `to`)
+// matched by both cron expressions `a` and `b`, which are evaluated with the
+// same UTC offset, in the time zone of `a`.
+func forEachCommonField(a, b *Expression, from, to time.Time, fn func(time.Time)) {
+	hours := intersect(a.hourList, b.hourList)
+	minutes := intersect(a.minuteList, b.minuteList)
+	seconds := intersect(a.secondList, b.secondList)
+	if len(hours) == 0 || len(minutes) == 0 || len(seconds) == 0 {
+		return
+	}
+
+	loc := a.location(from)
+	aDays, bDays := newDayMatcher(a), newDayMatcher(b)
+	first := from.In(loc)
+	month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, loc)
+	for ; month.Before(to); month = month.AddDate(0, 1, 0) {
+		y, m := month.Year(), int(month.Month())
+		if !sortContains(a.yearList, y) || !sortContains(b.yearList, y) {
+			continue
+		}
+		if !sortContains(a.monthList, m) || !sortContains(b.monthList, m) {
+			continue
+		}
+		for _, d := range intersect(aDays.days(y, m), bDays.days(y, m)) {
+			if !time.Date(y, time.Month(m), d+1, 0, 0, 0, 0, loc).After(from) || !time.Date(y, time.Month(m), d, 0, 0, 0, 0, loc).Before(to) {
+				continue
+			}
+			for _, h := range hours {
+				for _, mi := range minutes {
+					for _, s := range seconds {
+						t := time.Date(y, time.Month(m), d, h, mi, s, 0, loc)
+						// Skip wall clock times which do not exist because of
+						// daylight saving
+						if t.Hour() != h || t.Minute() != mi {
+							continue
+						}
+						if t.Before(from) || !t.Before(to) {
+							continue
+						}
+						fn(t)
+					}
+				}
+			}
+		}
+	}
+}
+
+// forEachBetween calls `fn` for each time instant within [`from`, `to`)
+// matched by `expr`, in chronological ascending order.
+func forEachBetween(expr *Expression, from, to time.Time, fn func(time.Time)) {
+	t := expr.Next(from.Add(-time.Second))
+	for !t.IsZero() && t.Before(to) {
+		if !t.Before(from) {
+			fn(t)
+		}
+		t = expr.Next(t)
+	}
+}
+
+/******************************************************************************/
+
+// matches reports whether the time instant `t` is matched by `expr`, `t` being
+// truncated to the second.
+func (expr *Expression) matches(t time.Time) bool {
+	if expr.reboot {
+		return false
+	}
+	if expr.every > 0 {
+		elapsed := t.Truncate(time.Second).Sub(expr.Anchor())
+		return elapsed >= 0 && elapsed%expr.every == 0
+	}
+	if expr.timeZone != nil {
+		t = t.In(expr.timeZone)
+	}
+	return sortContains(expr.yearList, t.Year()) &&
+		sortContains(expr.monthList, int(t.Month())) &&
+		sortContains(expr.calculateActualDaysOfMonth(t.Year(), int(t.Month())), t.Day()) &&
+		sortContains(expr.hourList, t.Hour()) &&
+		sortContains(expr.minuteList, t.Minute()) &&
+		sortContains(expr.secondList, t.Second())
+}
+
+// intersect returns the values common to the sorted lists `a` and `b`.
+func intersect(a, b []int) []int {
+	common := make([]int, 0, len(a))
+	for _, x := range a {
+		if sortContains(b, x) {
+			common = append(common, x)
+		}
+	}
+	return common
+}
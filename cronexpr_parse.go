@@ -17,11 +17,13 @@ package cronexpr
 /******************************************************************************/
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 /******************************************************************************/
@@ -200,32 +202,14 @@ var (
 	entryFinder                   = regexp.MustCompile(`[^,]+`)
 	entryDateFinder               = regexp.MustCompile(`[^-]+`)
 	entryTimeFinder               = regexp.MustCompile(`[^:]+`)
+	everyFinder                   = regexp.MustCompile(`(?i)^\s*@every\s+(\S+)\s*$`)
+	rebootFinder                  = regexp.MustCompile(`(?i)^\s*@reboot\s*$`)
 	layoutRegexp                  = make(map[string]*regexp.Regexp)
 	layoutRegexpLock              sync.Mutex
 )
 
 /******************************************************************************/
 
-var cronNormalizer = strings.NewReplacer(
-	"@yearly", "0 0 0 1 1 * *",
-	"@annually", "0 0 0 1 1 * *",
-	"@monthly", "0 0 0 1 * * *",
-	"@weekly", "0 0 0 * * 0 *",
-	"@daily", "0 0 0 * * * *",
-	"@hourly", "0 0 * * * * *")
-
-var systemdNormalizer = strings.NewReplacer(
-	"minutely", "*-*-* *:*:00",
-	"hourly", "*-*-* *:00:00",
-	"daily", "*-*-* 00:00:00",
-	"monthly", "*-*-01 00:00:00",
-	"weekly", "Mon *-*-* 00:00:00",
-	"yearly", "*-01-01 00:00:00",
-	"annually", "*-01-01 00:00:00",
-	"quarterly", "*-01,04,07,10-01 00:00:00",
-	"semiannually", "*-01,07-01 00:00:00",
-)
-
 type FieldType uint8
 
 const (
@@ -242,9 +226,30 @@ var systemdFieldsSig = map[FieldType]string{
 
 /******************************************************************************/
 
+// parseSpecial handles the aliases which do not stand for a set of field
+// values: `@every <duration>` and `@reboot`. The returned boolean is false if
+// `cronLine` is neither.
+func parseSpecial(cronLine string) (*Expression, bool, error) {
+	if rebootFinder.MatchString(cronLine) {
+		return &Expression{expression: cronLine, reboot: true}, true, nil
+	}
+	matches := everyFinder.FindStringSubmatch(cronLine)
+	if matches == nil {
+		return nil, false, nil
+	}
+	every, err := time.ParseDuration(matches[1])
+	if err != nil {
+		return nil, true, newSyntaxError("", strings.LastIndex(cronLine, matches[1]), matches[1], "invalid interval %s", matches[1])
+	}
+	if every < time.Second {
+		return nil, true, newSyntaxError("", strings.LastIndex(cronLine, matches[1]), matches[1], "invalid interval %s: must be at least 1s", matches[1])
+	}
+	return &Expression{expression: cronLine, every: every}, true, nil
+}
+
+/******************************************************************************/
+
 func (expr *Expression) normalyzeSystemd() error {
-	// Maybe one of the built-in aliases is being used
-	expr.expression = systemdNormalizer.Replace(expr.expression)
 	expr.expression = strings.ToLower(expr.expression)
 	return nil
 }
@@ -330,7 +335,7 @@ func genericFieldHandler(s string, desc fieldDescriptor) ([]int, error) {
 	for _, directive := range directives {
 		switch directive.kind {
 		case none:
-			return nil, fmt.Errorf("syntax error in %s field: '%s'", desc.name, s[directive.sbeg:directive.send])
+			return nil, newSyntaxError(desc.name, directive.sbeg, s[directive.sbeg:directive.send], "syntax error in %s field: '%s'", desc.name, s[directive.sbeg:directive.send])
 		case one:
 			populateOne(values, directive.first)
 		case span:
@@ -368,7 +373,7 @@ func (expr *Expression) dowFieldHandler(s string) error {
 				if len(pairs) > 0 {
 					populateOne(expr.specificWeekDaysOfWeek, (dowDescriptor.atoi(snormal[pairs[4]:pairs[5]])-1)*7+(dowDescriptor.atoi(snormal[pairs[2]:pairs[3]])%7))
 				} else {
-					return fmt.Errorf("syntax error in day-of-week field: '%s'", sdirective)
+					return newSyntaxError(dowDescriptor.name, directive.sbeg, sdirective, "syntax error in day-of-week field: '%s'", sdirective)
 				}
 			}
 		case one:
@@ -417,7 +422,7 @@ func (expr *Expression) domFieldHandler(s string) error {
 					if len(pairs) > 0 {
 						populateOne(expr.workdaysOfMonth, domDescriptor.atoi(snormal[pairs[2]:pairs[3]]))
 					} else {
-						return fmt.Errorf("syntax error in day-of-month field: '%s'", sdirective)
+						return newSyntaxError(domDescriptor.name, directive.sbeg, sdirective, "syntax error in day-of-month field: '%s'", sdirective)
 					}
 				}
 			}
@@ -462,7 +467,7 @@ func genericFieldParse(s string, desc fieldDescriptor) ([]*cronDirective, error)
 	// At least one entry must be present
 	indices := entryFinder.FindAllStringIndex(s, -1)
 	if len(indices) == 0 {
-		return nil, fmt.Errorf("%s field: missing directive", desc.name)
+		return nil, newSyntaxError(desc.name, 0, "", "%s field: missing directive", desc.name)
 	}
 
 	directives := make([]*cronDirective, 0, len(indices))
@@ -518,7 +523,7 @@ func genericFieldParse(s string, desc fieldDescriptor) ([]*cronDirective, error)
 			directive.last = desc.max
 			directive.step = atoi(snormal[pairs[2]:pairs[3]])
 			if directive.step < 1 || directive.step > desc.max {
-				return nil, fmt.Errorf("invalid interval %s", snormal)
+				return nil, newSyntaxError(desc.name, indices[i][0], s[indices[i][0]:indices[i][1]], "invalid interval %s", snormal)
 			}
 			directives = append(directives, &directive)
 			continue
@@ -531,7 +536,7 @@ func genericFieldParse(s string, desc fieldDescriptor) ([]*cronDirective, error)
 			directive.last = desc.max
 			directive.step = atoi(snormal[pairs[4]:pairs[5]])
 			if directive.step < 1 || directive.step > desc.max {
-				return nil, fmt.Errorf("invalid interval %s", snormal)
+				return nil, newSyntaxError(desc.name, indices[i][0], s[indices[i][0]:indices[i][1]], "invalid interval %s", snormal)
 			}
 			directives = append(directives, &directive)
 			continue
@@ -544,7 +549,7 @@ func genericFieldParse(s string, desc fieldDescriptor) ([]*cronDirective, error)
 			directive.last = desc.atoi(snormal[pairs[4]:pairs[5]])
 			directive.step = atoi(snormal[pairs[6]:pairs[7]])
 			if directive.step < 1 || directive.step > desc.max {
-				return nil, fmt.Errorf("invalid interval %s", snormal)
+				return nil, newSyntaxError(desc.name, indices[i][0], s[indices[i][0]:indices[i][1]], "invalid interval %s", snormal)
 			}
 			directives = append(directives, &directive)
 			continue
@@ -557,7 +562,7 @@ func genericFieldParse(s string, desc fieldDescriptor) ([]*cronDirective, error)
 			directive.last = desc.atoi(snormal[pairs[4]:pairs[5]])
 			directive.step = atoi(snormal[pairs[6]:pairs[7]])
 			if directive.step < 1 || directive.step > desc.max {
-				return nil, fmt.Errorf("invalid interval %s", snormal)
+				return nil, newSyntaxError(desc.name, indices[i][0], s[indices[i][0]:indices[i][1]], "invalid interval %s", snormal)
 			}
 			directives = append(directives, &directive)
 			continue
@@ -571,6 +576,22 @@ func genericFieldParse(s string, desc fieldDescriptor) ([]*cronDirective, error)
 
 /******************************************************************************/
 
+func newSyntaxError(field string, offset int, token, format string, a ...interface{}) *SyntaxError {
+	return &SyntaxError{Field: field, Offset: offset, Token: token, msg: fmt.Sprintf(format, a...)}
+}
+
+// shiftSyntaxError makes the offset of `err`, if it is a *SyntaxError,
+// relative to the expression rather than to the field at `base`.
+func shiftSyntaxError(err error, base int) error {
+	var serr *SyntaxError
+	if errors.As(err, &serr) {
+		serr.Offset += base
+	}
+	return err
+}
+
+/******************************************************************************/
+
 func makeLayoutRegexp(layout, value string) *regexp.Regexp {
 	layoutRegexpLock.Lock()
 	defer layoutRegexpLock.Unlock()
@@ -0,0 +1,278 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_stats.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"sort"
+	"time"
+)
+
+/******************************************************************************/
+
+// A Period is a calendar period over which matches are counted, see CountPer.
+type Period int
+
+const (
+	PerDay Period = iota
+	PerMonth
+	PerYear
+)
+
+// A PeriodCount is the number of matches of an expression within the period
+// starting at Start.
+type PeriodCount struct {
+	Start time.Time
+	Count int
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+/******************************************************************************/
+
+// MinInterval returns the shortest time between two consecutive matches of
+// `expr`, or zero if `expr` matches less than twice.
+//
+// Intervals are computed from the lists of matching seconds, minutes, hours
+// and days, without enumerating matches. They are measured in wall clock
+// time, that is, daylight saving shifts are not accounted for. The days
+// matched over all the years of the year field, at most 1970 to 2099, are
+// walked through, so that the cost grows with their number.
+func (expr *Expression) MinInterval() time.Duration {
+	min, _ := expr.intervals()
+	return min
+}
+
+// MaxInterval returns the longest time between two consecutive matches of
+// `expr`, or zero if `expr` matches less than twice.
+//
+// See MinInterval for how intervals are computed.
+func (expr *Expression) MaxInterval() time.Duration {
+	_, max := expr.intervals()
+	return max
+}
+
+func (expr *Expression) intervals() (min, max time.Duration) {
+	if expr.reboot || expr.neverFires {
+		return 0, 0
+	}
+	if expr.every > 0 {
+		return expr.every, expr.every
+	}
+
+	// Gaps between matches within a day
+	times := expr.timesOfDay()
+	if len(times) == 0 {
+		return 0, 0
+	}
+	minGap, maxGap := -1, -1
+	for i := 1; i < len(times); i++ {
+		gap := times[i] - times[i-1]
+		if minGap < 0 || gap < minGap {
+			minGap = gap
+		}
+		if gap > maxGap {
+			maxGap = gap
+		}
+	}
+
+	// Gaps between matching days, from the last match of a day to the
+	// first one of the next matching day
+	span := times[len(times)-1] - times[0]
+	days := newDayMatcher(expr)
+	lastDay := -1
+	for _, y := range expr.yearList {
+		for _, m := range expr.monthList {
+			for _, d := range days.days(y, m) {
+				day := int(time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay)
+				if lastDay >= 0 {
+					gap := (day-lastDay)*secondsPerDay - span
+					if minGap < 0 || gap < minGap {
+						minGap = gap
+					}
+					if gap > maxGap {
+						maxGap = gap
+					}
+				}
+				lastDay = day
+			}
+		}
+	}
+	if minGap < 0 || lastDay < 0 {
+		return 0, 0
+	}
+	return time.Duration(minGap) * time.Second, time.Duration(maxGap) * time.Second
+}
+
+/******************************************************************************/
+
+// Count returns the number of matches of `expr` within [`from`, `to`).
+//
+// Matches are counted day by day from the lists of matching seconds, minutes
+// and hours, without enumerating them. Time of day is wall clock time in the
+// time zone of `from`, unless `expr` is bound to a time zone. Months not
+// matched by `expr` are skipped, so that the cost grows with the number of
+// months within [`from`, `to`) and of days matched by `expr`, rather than with
+// the number of matches.
+func (expr *Expression) Count(from, to time.Time) int {
+	if expr.reboot || !from.Before(to) {
+		return 0
+	}
+	if expr.every > 0 {
+		anchor := expr.Anchor()
+		return countMultiples(from.Sub(anchor), to.Sub(anchor), expr.every)
+	}
+
+	loc := from.Location()
+	if expr.timeZone != nil {
+		loc = expr.timeZone
+	}
+	from, to = from.In(loc), to.In(loc)
+	times := expr.timesOfDay()
+	days := newDayMatcher(expr)
+
+	count := 0
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for ; !month.After(last); month = month.AddDate(0, 1, 0) {
+		y, m := month.Year(), int(month.Month())
+		if !sortContains(expr.yearList, y) || !sortContains(expr.monthList, m) {
+			continue
+		}
+		for _, d := range days.days(y, m) {
+			day := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+			if day.Before(first) || day.After(last) {
+				continue
+			}
+			// Matching seconds of the day within [`begin`, `end`)
+			begin, end := 0, secondsPerDay
+			if day.Equal(last) {
+				end = secondOfDay(to, false)
+			}
+			if day.Equal(first) {
+				begin = secondOfDay(from, true)
+			}
+			if begin == 0 && end == secondsPerDay {
+				count += len(times)
+			} else if begin < end {
+				count += sort.SearchInts(times, end) - sort.SearchInts(times, begin)
+			}
+		}
+	}
+	return count
+}
+
+// CountPer returns the number of matches of `expr` within each day, month or
+// year overlapping [`from`, `to`), in chronological ascending order. The
+// first and last periods are clipped to [`from`, `to`).
+//
+// CountPer returns nil if `period` is neither PerDay, PerMonth nor PerYear.
+func (expr *Expression) CountPer(period Period, from, to time.Time) []PeriodCount {
+	years, months, days, ok := period.length()
+	if !ok {
+		return nil
+	}
+	var counts []PeriodCount
+	loc := from.Location()
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	switch period {
+	case PerMonth:
+		start = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, loc)
+	case PerYear:
+		start = time.Date(from.Year(), time.January, 1, 0, 0, 0, 0, loc)
+	}
+	for start.Before(to) {
+		end := start.AddDate(years, months, days)
+		begin, stop := start, end
+		if begin.Before(from) {
+			begin = from
+		}
+		if stop.After(to) {
+			stop = to
+		}
+		counts = append(counts, PeriodCount{Start: start, Count: expr.Count(begin, stop)})
+		start = end
+	}
+	return counts
+}
+
+// length returns the length of `period`, as taken by time.Time.AddDate, and
+// false if `period` is unknown.
+func (period Period) length() (years, months, days int, ok bool) {
+	switch period {
+	case PerDay:
+		return 0, 0, 1, true
+	case PerMonth:
+		return 0, 1, 0, true
+	case PerYear:
+		return 1, 0, 0, true
+	}
+	return 0, 0, 0, false
+}
+
+// MatchingDays returns the days of `month` in `year` on which `expr` fires, in
+// ascending order, or nil if `expr` does not fire in that month, or is an
+// `@every` or `@reboot` expression, which are not bound to days.
+func (expr *Expression) MatchingDays(year int, month time.Month) []int {
+	if expr.reboot || expr.every > 0 || expr.neverFires {
+		return nil
+	}
+	if !sortContains(expr.yearList, year) || !sortContains(expr.monthList, int(month)) {
+		return nil
+	}
+	days := expr.calculateActualDaysOfMonth(year, int(month))
+	if len(days) == 0 {
+		return nil
+	}
+	// The list may be shared, see calculateActualDaysOfMonth
+	return append([]int(nil), days...)
+}
+
+/******************************************************************************/
+
+// timesOfDay returns the sorted seconds of the day matched by `expr`.
+func (expr *Expression) timesOfDay() []int {
+	times := make([]int, 0, len(expr.hourList)*len(expr.minuteList)*len(expr.secondList))
+	for _, h := range expr.hourList {
+		for _, m := range expr.minuteList {
+			for _, s := range expr.secondList {
+				times = append(times, h*3600+m*60+s)
+			}
+		}
+	}
+	return times
+}
+
+// secondOfDay returns the wall clock second of the day of `t`, rounded up to
+// the next second if `ceil` is true and `t` is not a whole second.
+func secondOfDay(t time.Time, ceil bool) int {
+	s := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	if ceil && t.Nanosecond() > 0 {
+		s += 1
+	}
+	return s
+}
+
+// countMultiples returns the number of non-negative multiples of `step`
+// within [`from`, `to`).
+func countMultiples(from, to, step time.Duration) int {
+	if from < 0 {
+		from = 0
+	}
+	if to <= from {
+		return 0
+	}
+	first := (from + step - 1) / step
+	last := (to - 1) / step
+	return int(last - first + 1)
+}
@@ -17,6 +17,8 @@ package cronexpr
 /******************************************************************************/
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"strings"
@@ -872,6 +874,413 @@ func TestInterval_Interval60Issue(t *testing.T) {
 	}
 }
 
+func TestEvery(t *testing.T) {
+	expr := MustParse("@every 1h30m")
+	assert.Equal(t, 90*time.Minute, expr.Every())
+
+	from := time.Date(2013, time.January, 1, 0, 10, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{
+		time.Date(2013, time.January, 1, 1, 30, 0, 0, time.UTC),
+		time.Date(2013, time.January, 1, 3, 0, 0, 0, time.UTC),
+		time.Date(2013, time.January, 1, 4, 30, 0, 0, time.UTC),
+	}, expr.NextN(from, 3))
+
+	start := time.Date(2013, time.January, 1, 0, 5, 0, 0, time.UTC)
+	anchored := expr.WithAnchor(start)
+	assert.Equal(t, start, anchored.Next(start.Add(-time.Hour)))
+	assert.Equal(t, start.Add(90*time.Minute), anchored.Next(start))
+	assert.Equal(t, time.Unix(0, 0).UTC(), expr.Anchor())
+
+	for _, bad := range []string{"@every", "@every 1x", "@every 500ms", "@every -1h"} {
+		_, err := Parse(bad)
+		assert.Errorf(t, err, "Parse(%q) should fail", bad)
+	}
+}
+
+func TestReboot(t *testing.T) {
+	expr := MustParse("@reboot")
+	assert.True(t, expr.IsReboot())
+	assert.True(t, expr.Next(time.Now()).IsZero())
+	assert.Empty(t, expr.NextN(time.Now(), 3))
+	assert.False(t, MustParse("@daily").IsReboot())
+	assert.True(t, MustParse("@REBOOT").IsReboot())
+	assert.Equal(t, time.Hour, MustParse("@Every 1h").Every())
+}
+
+func TestRegisterAlias(t *testing.T) {
+	require.NoError(t, RegisterAlias("@market-open", "30 9 * * mon-fri"))
+	t.Cleanup(func() { cronAliases.unregister("@market-open") })
+	assert.Error(t, RegisterAlias("@market-open", "0 10 * * mon-fri"), "duplicate alias")
+	assert.Error(t, RegisterAlias("market-open", "0 10 * * *"), "missing @")
+	assert.Error(t, RegisterAlias("@reboot", "0 10 * * *"), "reserved alias")
+	assert.Error(t, RegisterAlias("@Every", "0 10 * * *"), "reserved alias")
+	assert.Error(t, RegisterAlias("@payroll", "0 10 32 * *"), "invalid expansion")
+
+	from := time.Date(2013, time.January, 4, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2013, time.January, 7, 9, 30, 0, 0, time.UTC), MustParse("@market-open").Next(from))
+	assert.Contains(t, Aliases(), Alias{Name: "@market-open", Expansion: "30 9 * * mon-fri"})
+	assert.Contains(t, Aliases(), Alias{Name: "@daily", Expansion: "0 0 0 * * * *"})
+
+	// Only whole fields are expanded
+	_, err := Parse("@market-opener")
+	assert.Error(t, err)
+
+	require.NoError(t, RegisterSystemdAlias("Payday", "*-*-25 09:00"))
+	t.Cleanup(func() { systemdAliases.unregister("payday") })
+	assert.Equal(t, time.Date(2013, time.January, 25, 9, 0, 0, 0, time.UTC), MustParseSystemd("payday").Next(from))
+	assert.Contains(t, SystemdAliases(), Alias{Name: "payday", Expansion: "*-*-25 09:00"})
+	assert.Error(t, RegisterSystemdAlias("mon", "*-*-25 09:00"), "reserved alias")
+}
+
+func TestEqualAndContains(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		contains bool
+		equal    bool
+	}{
+		{"0 */6 * * *", "0 0,6,12,18 * * *", true, true},
+		{"0 0 * * *", "0 0 * * 1-5", true, false},
+		{"0 0 * * 1-5", "0 0 * * *", false, false},
+		{"0 0 28-31 * *", "0 0 L * *", true, false},
+		{"0 0 1-3 * *", "0 0 1W * *", true, false},
+		{"0 0 * * 1-5", "0 0 1W * *", true, false},
+		{"0 0 * * 5", "0 0 * * 5L", true, false},
+		{"0 0 * * 5", "0 0 * * 5#2", true, false},
+		{"0 0 1-7 * *", "0 0 * * 1#1", true, false},
+		{"0 0 1,15 * *", "0 0 1 * 0", false, false},
+		{"0 0 * * 0", "0 0 1 * 0", false, false},
+		{"0 0 * * 0,1", "0 0 * * sun,mon", true, true},
+		{"0 0 * * 0", "0 0 * * 7", true, true},
+		{"0 0 1 * *", "0 0 1 * * 2013", true, false},
+		{"0 0 1 * * 2013", "0 0 1 * * 2014", false, false},
+		{"* * * * *", "*/5 * * * * * *", false, false},
+		{"* * * * *", "*/5 * * * * *", true, false},
+		{"0 0 * * *", "0 0 30 2 *", true, false},
+		{"0 0 31 4 *", "0 0 30 2 *", true, true},
+		{"@every 1h", "@every 2h", true, false},
+		{"@every 2h", "@every 1h", false, false},
+		{"@every 1h", "0 * * * *", false, false},
+		{"@reboot", "@reboot", true, true},
+		{"@reboot", "0 * * * *", false, false},
+	}
+	for _, c := range cases {
+		a, b := MustParse(c.a), MustParse(c.b)
+		assert.Equalf(t, c.contains, Contains(a, b), "Contains(%q, %q)", c.a, c.b)
+		assert.Equalf(t, c.equal, Equal(a, b), "Equal(%q, %q)", c.a, c.b)
+	}
+
+	assert.True(t, Equal(MustParseSystemd("*-*-* 06:00:00"), MustParse("0 6 * * *")))
+	assert.True(t, Equal(MustParseSystemd("Mon..Fri *-*-* 06:00"), MustParse("0 6 * * 1-5")))
+
+	// Time zones are compared as by Overlaps, by UTC offset rather than by
+	// name
+	utc, etc := MustParseSystemd("*-*-* 02:00:00 UTC"), MustParseSystemd("*-*-* 02:00:00 Etc/UTC")
+	assert.True(t, Equal(utc, etc))
+	shifted := MustParseSystemd("*-*-* 02:00:00 UTC")
+	shifted.timeZone = time.FixedZone("UTC", 3600)
+	assert.False(t, Contains(utc, shifted))
+	assert.False(t, Contains(MustParse("0 2 * * *"), utc))
+	from := time.Date(2013, time.July, 1, 0, 0, 0, 0, time.UTC)
+	assert.Len(t, Overlaps(map[string]*Expression{"utc": utc, "etc": etc}, from, from.AddDate(0, 0, 8)), 8)
+}
+
+func TestOverlaps(t *testing.T) {
+	exprs := map[string]*Expression{
+		"backup":  MustParse("0 2 * * *"),
+		"reindex": MustParse("0 */2 * * 1"),
+		"report":  MustParse("0 2,14 1 * *"),
+		"vacuum":  MustParse("@every 12h"),
+		"warmup":  MustParse("@reboot"),
+	}
+	from := time.Date(2013, time.July, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2013, time.July, 9, 0, 0, 0, 0, time.UTC)
+
+	overlaps := Overlaps(exprs, from, to)
+	expected := []Overlap{
+		{time.Date(2013, time.July, 1, 0, 0, 0, 0, time.UTC), []string{"reindex", "vacuum"}},
+		{time.Date(2013, time.July, 1, 2, 0, 0, 0, time.UTC), []string{"backup", "reindex", "report"}},
+		{time.Date(2013, time.July, 1, 12, 0, 0, 0, time.UTC), []string{"reindex", "vacuum"}},
+		{time.Date(2013, time.July, 1, 14, 0, 0, 0, time.UTC), []string{"reindex", "report"}},
+		{time.Date(2013, time.July, 8, 0, 0, 0, 0, time.UTC), []string{"reindex", "vacuum"}},
+		{time.Date(2013, time.July, 8, 2, 0, 0, 0, time.UTC), []string{"backup", "reindex"}},
+		{time.Date(2013, time.July, 8, 12, 0, 0, 0, time.UTC), []string{"reindex", "vacuum"}},
+	}
+	assert.Equal(t, expected, overlaps)
+
+	// Differently zoned expressions are compared instant by instant
+	exprs = map[string]*Expression{
+		"utc":    MustParseSystemd("*-*-* 02:00:00 UTC"),
+		"backup": MustParse("0 2 * * *"),
+	}
+	assert.Len(t, Overlaps(exprs, from, to), 8)
+
+	// Time zones are compared by UTC offset rather than by name
+	shifted := MustParse("0 2 * * *")
+	shifted.timeZone = time.FixedZone("UTC", 3600)
+	exprs = map[string]*Expression{
+		"utc":     MustParseSystemd("*-*-* 02:00:00 UTC"),
+		"shifted": shifted,
+	}
+	assert.Empty(t, Overlaps(exprs, from, to))
+	exprs["etc"] = MustParseSystemd("*-*-* 02:00:00 Etc/UTC")
+	assert.Len(t, Overlaps(exprs, from, to), 8)
+}
+
+func TestForEachOverlap(t *testing.T) {
+	exprs := map[string]*Expression{
+		"a": MustParse("* * * * *"),
+		"b": MustParse("* * * * *"),
+	}
+	from := time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var overlaps []Overlap
+	ForEachOverlap(exprs, from, from.AddDate(1, 0, 0), func(overlap Overlap) bool {
+		overlaps = append(overlaps, overlap)
+		return len(overlaps) < 3
+	})
+	require.Len(t, overlaps, 3)
+	assert.Equal(t, Overlap{from.Add(2 * time.Minute), []string{"a", "b"}}, overlaps[2])
+
+	count := 0
+	ForEachOverlap(exprs, from, from.AddDate(0, 0, 2), func(Overlap) bool {
+		count += 1
+		return true
+	})
+	assert.Equal(t, 2*24*60, count)
+}
+
+func TestIntervals(t *testing.T) {
+	cases := []struct {
+		expr     string
+		min, max time.Duration
+	}{
+		{"* * * * *", time.Minute, time.Minute},
+		{"*/7 * * * *", 4 * time.Minute, 7 * time.Minute},
+		{"0 9-17 * * mon-fri", time.Hour, 64 * time.Hour},
+		{"0 0 29 2 *", 1461 * 24 * time.Hour, 1461 * 24 * time.Hour},
+		{"0 0 1 1 * 2013", 0, 0},
+		{"* * 1 1 * 2013", time.Minute, time.Minute},
+		{"* * 30 2 *", 0, 0},
+		{"* * 31 4,6 * 2013", 0, 0},
+		{"@every 90m", 90 * time.Minute, 90 * time.Minute},
+		{"@reboot", 0, 0},
+	}
+	for _, c := range cases {
+		expr := MustParse(c.expr)
+		assert.Equalf(t, c.min, expr.MinInterval(), "MinInterval(%q)", c.expr)
+		assert.Equalf(t, c.max, expr.MaxInterval(), "MaxInterval(%q)", c.expr)
+	}
+}
+
+func TestCount(t *testing.T) {
+	from := time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2014, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		expr     string
+		from, to time.Time
+	}{
+		{"*/5 * * * *", from, to},
+		{"0 9-17 * * mon-fri", from, to},
+		{"0 0 * * 5#5", from, to},
+		{"0 0 L * *", from, to},
+		{"30 */2 * * *", from.Add(90*time.Minute + time.Nanosecond), from.Add(26*time.Hour + 30*time.Minute)},
+		{"0 0 1,15 * *", from.AddDate(0, 0, 14).Add(time.Second), to.AddDate(0, 0, 14)},
+		{"@every 7h", from, to},
+	}
+	for _, c := range cases {
+		expr := MustParse(c.expr)
+		expected := 0
+		forEachBetween(expr, c.from, c.to, func(time.Time) { expected += 1 })
+		assert.Equalf(t, expected, expr.Count(c.from, c.to), "Count(%q)", c.expr)
+	}
+
+	counts := MustParse("0 0 * * mon").CountPer(PerMonth, from, to)
+	require.Len(t, counts, 12)
+	assert.Equal(t, PeriodCount{Start: from, Count: 4}, counts[0])
+	assert.Equal(t, 5, counts[3].Count)
+	assert.Equal(t, 5, counts[8].Count)
+
+	counts = MustParse("0 12 * * *").CountPer(PerDay, from.Add(13*time.Hour), from.Add(48*time.Hour))
+	assert.Equal(t, []PeriodCount{{from, 0}, {from.AddDate(0, 0, 1), 1}}, counts)
+
+	// Months not matched are skipped, however long the range
+	assert.Equal(t, 1, MustParse("0 0 0 1 1 * 2099").Count(from, time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, MustParse("0 12 * * *").CountPer(Period(7), from, to))
+}
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		expr  string
+		kinds []WarningKind
+	}{
+		{"0 0 * * *", nil},
+		{"*/15 */6 * * *", nil},
+		{"0 0 31 2 *", []WarningKind{NeverFires}},
+		{"0 0 29 2 *", []WarningKind{SomeYearsOnly}},
+		{"0 0 30 * 5", []WarningKind{DayOfMonthAndWeek}},
+		{"*/7 * * * *", []WarningKind{UnevenStep}},
+		{"5/20 */5 * */5 */2", []WarningKind{UnevenStep, UnevenStep, UnevenStep}},
+		{"10-20/15 * * * *", []WarningKind{StepExceedsRange}},
+		{"1-5,3 * * * *", []WarningKind{DuplicateEntry}},
+		{"0 0 * * 0,7", []WarningKind{DuplicateEntry}},
+		{"0 0 * * 0,5-7", []WarningKind{DuplicateEntry}},
+		{"0 0 * * 0-7", nil},
+		{"0 0 * * 1-7/6", nil},
+		{"@every 7m", nil},
+	}
+	for _, c := range cases {
+		var kinds []WarningKind
+		for _, warning := range Lint(MustParse(c.expr), nil) {
+			kinds = append(kinds, warning.Kind)
+		}
+		assert.Equalf(t, c.kinds, kinds, "Lint(%q)", c.expr)
+	}
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	warnings := Lint(MustParse("30 2 * * * 2013"), paris)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, DaylightSavingGap, warnings[0].Kind)
+	assert.Equal(t, "02:30:00 does not exist on 2013-03-31 in Europe/Paris because of daylight saving", warnings[0].Message)
+	assert.Empty(t, Lint(MustParse("30 3 * * * 2013"), paris))
+	assert.Empty(t, Lint(MustParse("30 2 * * sat 2013"), paris))
+}
+
+func TestSatisfiable(t *testing.T) {
+	for _, expr := range []string{"0 0 30 2 *", "0 0 31 4,6,9,11 *", "0 0 29 2 * 2097-2099", "0 5-2 * * *"} {
+		assert.Falsef(t, MustParse(expr).IsSatisfiable(), "IsSatisfiable(%q)", expr)
+		assert.Truef(t, MustParse(expr).Next(time.Now()).IsZero(), "Next(%q)", expr)
+		_, err := ParseStrict(expr)
+		assert.Truef(t, errors.Is(err, ErrNeverFires), "ParseStrict(%q) returned %v", expr, err)
+	}
+	for _, expr := range []string{"0 0 29 2 *", "0 0 * * * 1980", "0 0 30 2 5", "@every 1h", "@reboot"} {
+		assert.Truef(t, MustParse(expr).IsSatisfiable(), "IsSatisfiable(%q)", expr)
+		_, err := ParseStrict(expr)
+		assert.NoErrorf(t, err, "ParseStrict(%q)", expr)
+	}
+	_, err := ParseSystemdStrict("*-02-30")
+	assert.True(t, errors.Is(err, ErrNeverFires))
+	_, err = ParseStrict("0 0 32 2 *")
+	assert.False(t, errors.Is(err, ErrNeverFires))
+}
+
+func TestTicker(t *testing.T) {
+	ticker := NewTicker(context.Background(), MustParse("* * * * * * *"))
+	first := <-ticker.C
+	second := <-ticker.C
+	ticker.Stop()
+	assert.Equal(t, time.Second, second.Sub(first))
+	assert.Zero(t, second.Nanosecond())
+	assert.WithinDuration(t, time.Now(), second, time.Second)
+	for range ticker.C {
+	}
+
+	// No more matches
+	ticker = NewTicker(context.Background(), MustParse("* * * * * 1980"))
+	_, ok := <-ticker.C
+	assert.False(t, ok)
+
+	// Cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker = NewTicker(ctx, MustParse("@yearly"))
+	cancel()
+	_, ok = <-ticker.C
+	assert.False(t, ok)
+}
+
+func TestTickerOwnExpression(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(base)
+	expr := MustParse("0 0 L * *")
+	ticker := NewTickerWithClock(context.Background(), expr, clock)
+	defer ticker.Stop()
+	// The caller keeps using `expr` while the ticker does, see `go test -race`
+	for month := 1; month <= 12; month++ {
+		clock.Advance(31 * 24 * time.Hour)
+		expr.Next(base.AddDate(0, month, 0))
+		<-ticker.C
+	}
+}
+
+func TestTimer(t *testing.T) {
+	timer := NewTimer(context.Background(), MustParse("* * * * * * *"))
+	fired, ok := <-timer.C
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now(), fired, time.Second)
+	_, ok = <-timer.C
+	assert.False(t, ok)
+
+	timer = NewTimer(context.Background(), MustParse("@yearly"))
+	timer.Stop()
+	_, ok = <-timer.C
+	assert.False(t, ok)
+}
+
+func TestFakeClock(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(base)
+	timer := clock.NewTimer(time.Minute)
+	after := clock.After(time.Hour)
+	assert.Equal(t, 2, clock.Timers())
+
+	clock.Advance(30 * time.Second)
+	assert.Empty(t, timer.C())
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, base.Add(time.Minute), <-timer.C())
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Minute))
+	assert.True(t, timer.Stop())
+	clock.Set(base.Add(2 * time.Hour))
+	assert.Empty(t, timer.C())
+	assert.Equal(t, base.Add(2*time.Hour), <-after)
+	assert.Zero(t, clock.Timers())
+}
+
+func TestTickerWithFakeClock(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(base)
+	ticker := NewTickerWithClock(context.Background(), MustParse("@daily"), clock)
+	for day := 1; day <= 365; day++ {
+		clock.Advance(24 * time.Hour)
+		assert.Equal(t, base.AddDate(0, 0, day), <-ticker.C)
+	}
+	ticker.Stop()
+
+	timer := NewTimerWithClock(context.Background(), MustParse("0 0 1 * *"), clock)
+	clock.Advance(31 * 24 * time.Hour)
+	assert.Equal(t, time.Date(2014, time.February, 1, 0, 0, 0, 0, time.UTC), <-timer.C)
+	_, ok := <-timer.C
+	assert.False(t, ok)
+}
+
+func TestPrev(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	froms := []time.Time{
+		time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2013, time.March, 31, 2, 30, 0, 0, paris),
+		time.Date(2013, time.October, 27, 2, 30, 0, 500, paris),
+		time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, test := range crontests {
+		expr := MustParse(test.expr)
+		for _, from := range froms {
+			prev := expr.Prev(from)
+			require.Falsef(t, prev.IsZero(), "(%q).Prev(%v)", test.expr, from)
+			assert.Truef(t, prev.Before(from), "(%q).Prev(%v) = %v", test.expr, from, prev)
+			assert.Falsef(t, expr.Next(prev).Before(from), "(%q).Prev(%v) = %v is not the closest", test.expr, from, prev)
+		}
+	}
+
+	from := time.Date(2013, time.September, 2, 8, 44, 32, 0, time.UTC)
+	assert.Equal(t, time.Date(2013, time.August, 31, 0, 0, 0, 0, time.UTC), MustParse("0 0 * * 6#5").Prev(from))
+	assert.Equal(t, time.Date(2013, time.September, 2, 8, 44, 31, 0, time.UTC), MustParse("* * * * * * *").Prev(from))
+	assert.Equal(t, time.Date(1980, time.December, 31, 23, 59, 0, 0, time.UTC), MustParse("* * * * * 1980").Prev(from))
+	assert.True(t, MustParse("* * * * * 2050").Prev(from).IsZero())
+	assert.Equal(t, time.Date(2013, time.September, 2, 7, 30, 0, 0, time.UTC), MustParse("@every 90m").Prev(from))
+	assert.True(t, MustParse("@reboot").Prev(from).IsZero())
+}
+
 /******************************************************************************/
 
 var benchmarkExpressions = []string{
@@ -907,3 +1316,261 @@ func BenchmarkNext(b *testing.B) {
 		next = expr.Next(next)
 	}
 }
+
+func TestNormalized(t *testing.T) {
+	cron := map[string]string{
+		"*/15 9-17 * * mon-fri":   "*/15 9-17 * * 1-5",
+		"@daily":                  "0 0 * * *",
+		"0 0 L * 5#3":             "0 0 L * 5#3",
+		"30 2 * * 1,3,5":          "30 2 * * 1,3,5",
+		"0 0 1-31 * 1":            "0 0 1-31 * 1",
+		"0 0 0 1 jan * 2020-2025": "0 0 0 1 1 * 2020-2025",
+		"5/15 * * * *":            "5/15 * * * *",
+		"*/10 * * * * * *":        "*/10 * * * * * *",
+		"@every 90m":              "@every 1h30m0s",
+		"@reboot":                 "@reboot",
+		"0  5-2 * * *":            "0 5-2 * * *",
+		"0 0 * * 5-2":             "0 0 * * 5-2",
+		"5-2 * * * * * *":         "5-2 * * * * * *",
+	}
+	for line, expected := range cron {
+		assert.Equal(t, expected, MustParse(line).Normalized(), line)
+		_, err := Parse(expected)
+		assert.NoError(t, err, line)
+	}
+	systemd := map[string]string{
+		"daily":                      "*-*-* 00:00:00",
+		"mon..fri *-*-* 09..17:0/15": "Mon..Fri *-*-* 09..17:00/15:00",
+		"sun,sat 2024-*-01 12:00":    "Sat,Sun 2024-*-01 12:00:00",
+		"mon *-*-* 00:00:00 utc":     "Mon *-*-* 00:00:00 UTC",
+	}
+	for line, expected := range systemd {
+		assert.Equal(t, expected, MustParseSystemd(line).Normalized(), line)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	cron := map[string]string{
+		"*/15 9-17 * * mon-fri":   "every 15 minutes past hours 9 through 17, on Monday through Friday",
+		"@daily":                  "at 00:00, every day",
+		"0 0 L * 5#3":             "at 00:00, on the last day of the month or on the third Friday of the month",
+		"30 2 * * 1,3,5":          "at 02:30, on Monday, Wednesday and Friday",
+		"* * * * *":               "every minute",
+		"0 * * * *":               "at minute 0 past every hour",
+		"*/10 * * * * * *":        "every 10 seconds",
+		"* 9 * * *":               "every minute during hour 9",
+		"0 12 15W 3/3 *":          "at 12:00, on the weekday nearest day 15 of the month, in March, June, September and December",
+		"0 0 0 1 jan * 2020-2025": "at 00:00, on day 1 of the month, in January, in 2020 through 2025",
+		"@every 90m":              "every 1h30m0s",
+		"@reboot":                 "at startup",
+		"5-2 * * * * * *":         "never",
+		"0 0 30 2 *":              "never",
+	}
+	for line, expected := range cron {
+		assert.Equal(t, expected, MustParse(line).Describe(), line)
+	}
+	assert.Equal(t, "at 00:00, on Monday (UTC)", MustParseSystemd("mon *-*-* 00:00:00 utc").Describe())
+}
+
+func TestSyntaxError(t *testing.T) {
+	cases := []struct {
+		expr   string
+		field  string
+		offset int
+		token  string
+	}{
+		{"* * *", "", 5, ""},
+		{"0 25 * * *", "hour", 2, "25"},
+		{"0 0 1,xx * *", "day-of-month", 6, "xx"},
+		{"*/0 * * * *", "minute", 0, "*/0"},
+		{"0 0 * * 1,9#2", "day-of-week", 10, "9#2"},
+		{"0 0 0 1 1 * 2000,1900", "year", 17, "1900"},
+		{"@every 1x", "", 7, "1x"},
+		{"@every 10ms", "", 7, "10ms"},
+		// Offsets are in the expression as written, aliases unexpanded
+		{"@noon 2000,1900", "year", 11, "1900"},
+		{"0 0 0 @noon", "day-of-month", 6, "@noon"},
+		{"@noon 1 2 3", "year", 8, "2"},
+	}
+	require.NoError(t, RegisterAlias("@noon", "0 12 * * *"))
+	t.Cleanup(func() { cronAliases.unregister("@noon") })
+	for _, c := range cases {
+		_, err := Parse(c.expr)
+		var serr *SyntaxError
+		require.Truef(t, errors.As(err, &serr), "Parse(%q) returned %v", c.expr, err)
+		assert.Equalf(t, c.field, serr.Field, "Parse(%q).Field", c.expr)
+		assert.Equalf(t, c.offset, serr.Offset, "Parse(%q).Offset", c.expr)
+		assert.Equalf(t, c.token, serr.Token, "Parse(%q).Token", c.expr)
+	}
+
+	_, err := ParseSystemd("mon *-*-* 09:75")
+	var serr *SyntaxError
+	require.True(t, errors.As(err, &serr), err)
+	assert.Equal(t, "minute", serr.Field)
+	assert.Equal(t, 13, serr.Offset)
+	assert.Equal(t, "75", serr.Token)
+}
+
+func TestMatchingDays(t *testing.T) {
+	assert.Equal(t, []int{3, 26}, MustParse("0 9 * * 2#1,4L").MatchingDays(2013, time.September))
+	assert.Equal(t, []int{1, 2, 9, 15, 16, 23, 30}, MustParse("0 0 1,15 * sat").MatchingDays(2013, time.November))
+	assert.Len(t, MustParse("* * * * *").MatchingDays(2024, time.February), 29)
+	assert.Nil(t, MustParse("0 0 30 * *").MatchingDays(2013, time.February))
+	assert.Nil(t, MustParse("0 0 1 6 *").MatchingDays(2013, time.May))
+	assert.Nil(t, MustParse("0 0 * * * 2020").MatchingDays(2013, time.May))
+	assert.Nil(t, MustParse("@every 1h").MatchingDays(2013, time.May))
+
+	days := MustParse("* * * * *").MatchingDays(2013, time.May)
+	days[0] = 0
+	assert.Equal(t, 1, MustParse("* * * * *").MatchingDays(2013, time.May)[0])
+}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		expr     string
+		to       Dialect
+		expected string
+	}{
+		{"*/15 9-17 * * mon-fri", Cron, "*/15 9-17 * * 1-5"},
+		{"*/15 9-17 * * mon-fri", Quartz, "0 0/15 9-17 ? * 2-6"},
+		{"*/15 9-17 * * mon-fri", Systemd, "Mon..Fri *-*-* 09..17:00/15:00"},
+		{"*/15 9-17 * * mon-fri", EventBridge, "cron(0/15 9-17 ? * 2-6 *)"},
+		{"0 12 1,15 * *", Quartz, "0 0 12 1,15 * ?"},
+		{"0 12 L * *", Systemd, "*-*~01 12:00:00"},
+		{"0 0 9 * * 5#3 2025", Quartz, "0 0 9 ? * 6#3 2025"},
+		{"0 0 9 * * 5#3 2025", EventBridge, "cron(0 9 ? * 6#3 2025)"},
+		{"0 18 LW * *", Quartz, "0 0 18 LW * ?"},
+		{"@every 90m", EventBridge, "rate(90 minutes)"},
+		{"@every 48h", EventBridge, "rate(2 days)"},
+		{"@reboot", Cron, "@reboot"},
+	}
+	for _, c := range cases {
+		converted, err := Convert(MustParse(c.expr), c.to)
+		require.NoErrorf(t, err, "Convert(%q, %s)", c.expr, c.to)
+		assert.Equalf(t, c.expected, converted, "Convert(%q, %s)", c.expr, c.to)
+	}
+
+	failures := []struct {
+		expr       string
+		to         Dialect
+		constructs []string
+	}{
+		{"0 0 9 * * 5#3 *", Cron, []string{"5#3"}},
+		{"0 0 9 * * 5#3 *", Systemd, []string{"5#3"}},
+		{"*/10 * * * * * *", Cron, []string{"*/10"}},
+		{"*/10 * * * * * *", EventBridge, []string{"*/10"}},
+		{"0 0 LW * *", EventBridge, []string{"LW"}},
+		{"0 0 1,L * *", Quartz, []string{"L"}},
+		{"0 0 15W * *", Systemd, []string{"15W"}},
+		{"0 0 1 * 1", Quartz, []string{"1 * 1"}},
+		{"0 0 1 * 1 2030", Cron, []string{"2030"}},
+		{"@every 90s", EventBridge, []string{"@every 1m30s"}},
+		{"@reboot", Systemd, []string{"@reboot"}},
+		{"5-2 * * * * * *", Cron, []string{"5-2 * * * * * *"}},
+	}
+	for _, c := range failures {
+		_, err := Convert(MustParse(c.expr), c.to)
+		var cerr *ConvertError
+		require.Truef(t, errors.As(err, &cerr), "Convert(%q, %s) returned %v", c.expr, c.to, err)
+		var constructs []string
+		for _, u := range cerr.Unsupported {
+			constructs = append(constructs, u.Construct)
+		}
+		assert.Equalf(t, c.constructs, constructs, "Convert(%q, %s)", c.expr, c.to)
+	}
+
+	_, err := Convert(MustParseSystemd("mon *-*-* 09:00 utc"), Cron)
+	assert.EqualError(t, err, "cannot convert to cron: 'UTC' (time zone)")
+
+	// Constructs are quoted as written in the dialect of the expression
+	expr, err := ParseDialect("0 0 12 ? * 6#3", Quartz)
+	require.NoError(t, err)
+	_, err = Convert(expr, Cron)
+	assert.EqualError(t, err, "cannot convert to cron: '6#3' in day-of-week field (nth day of the week of the month)")
+	expr, err = ParseDialect("cron(0 12 ? * 2L *)", EventBridge)
+	require.NoError(t, err)
+	_, err = Convert(expr, EventBridge)
+	assert.EqualError(t, err, "cannot convert to eventbridge: '2L' in day-of-week field (last day of the week of the month)")
+}
+
+func TestParseDialect(t *testing.T) {
+	cases := []struct {
+		line    string
+		dialect Dialect
+		cron    string
+	}{
+		{"0 0/15 9-17 ? * 2-6", Quartz, "*/15 9-17 * * mon-fri"},
+		{"0 0 9 ? * 6#3 2025", Quartz, "0 0 9 * * 5#3 2025"},
+		{"0 0 12 ? * SUN,7", Quartz, "0 12 * * 0,6"},
+		{"0 0 12 ? * L", Quartz, "0 12 * * 6"},
+		{"cron(0 12 ? * l *)", EventBridge, "0 12 * * 6"},
+		{"cron(0 18 L * ? *)", EventBridge, "0 18 L * *"},
+		{"rate(5 minutes)", EventBridge, "@every 5m"},
+		{"Mon..Fri 9:00", Systemd, "0 9 * * 1-5"},
+	}
+	for _, c := range cases {
+		expr, err := ParseDialect(c.line, c.dialect)
+		require.NoErrorf(t, err, "ParseDialect(%q, %s)", c.line, c.dialect)
+		assert.Truef(t, Equal(MustParse(c.cron), expr), "ParseDialect(%q, %s) = %s", c.line, c.dialect, expr.Normalized())
+	}
+	for _, line := range []string{"0 18 L * ? *", "cron(0 18 L * ?)", "rate(5 weeks)"} {
+		_, err := ParseDialect(line, EventBridge)
+		assert.Errorf(t, err, "ParseDialect(%q, eventbridge)", line)
+	}
+
+	// Syntax errors quote the token as written
+	_, err := ParseDialect("0 0 12 ? * 2,6#9", Quartz)
+	var serr *SyntaxError
+	require.True(t, errors.As(err, &serr), err)
+	assert.Equal(t, "6#9", serr.Token)
+	assert.EqualError(t, err, "syntax error in day-of-week field: '6#9'")
+
+	d, err := LookupDialect("EventBridge")
+	require.NoError(t, err)
+	assert.Equal(t, EventBridge, d)
+	_, err = LookupDialect("fcron")
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	from := time.Date(2013, time.September, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 14)
+	type change struct {
+		t    time.Time
+		side DiffSide
+	}
+	var changes []change
+	common := Diff(MustParse("0 9 * * 1-5"), MustParse("30 9 * * mon,wed-sat"), from, to, func(t time.Time, side DiffSide) bool {
+		changes = append(changes, change{t, side})
+		return true
+	})
+	assert.Equal(t, 0, common)
+	assert.Len(t, changes, 10+10)
+	assert.Equal(t, change{from.Add(9 * time.Hour), OnlyOld}, changes[0])
+	assert.Equal(t, change{from.Add(9*time.Hour + 30*time.Minute), OnlyNew}, changes[1])
+
+	changes = nil
+	common = Diff(MustParse("0 9 * * 1-5"), MustParse("0 9 * * 1-6"), from, to, func(t time.Time, side DiffSide) bool {
+		changes = append(changes, change{t, side})
+		return true
+	})
+	assert.Equal(t, 10, common)
+	assert.Equal(t, []change{
+		{time.Date(2013, time.September, 7, 9, 0, 0, 0, time.UTC), OnlyNew},
+		{time.Date(2013, time.September, 14, 9, 0, 0, 0, time.UTC), OnlyNew},
+	}, changes)
+
+	// Stopping early
+	n := 0
+	common = Diff(MustParse("0 * * * *"), MustParse("30 * * * *"), from, to, func(time.Time, DiffSide) bool {
+		n += 1
+		return n < 3
+	})
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 0, common)
+
+	assert.Equal(t, 14, Diff(MustParse("@daily"), MustParse("0 0 * * *"), from, to, func(time.Time, DiffSide) bool {
+		t.Fatal("no difference expected")
+		return false
+	}))
+}
@@ -0,0 +1,150 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: cronexpr_ticker.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package cronexpr
+
+/******************************************************************************/
+
+import (
+	"context"
+	"time"
+)
+
+/******************************************************************************/
+
+// maxTickerWait bounds how long tickers and timers sleep at once, so that they
+// notice when the wall clock is set forward or back, time.Timer being driven
+// by the monotonic clock.
+const maxTickerWait = 30 * time.Second
+
+/******************************************************************************/
+
+// A Ticker holds a channel which delivers the time instants matched by an
+// expression, as they occur.
+//
+// Like time.Ticker, a Ticker drops ticks to make up for slow receivers: an
+// instant which elapses while the previous one is still pending in the channel
+// is skipped.
+type Ticker struct {
+	// C receives each matched time instant, not the current time. It is
+	// closed once the ticker stops, see NewTicker.
+	C      <-chan time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTicker returns a new Ticker delivering the time instants matched by
+// `expr` from now on. The caller remains free to use `expr` meanwhile.
+//
+// The ticker stops and closes its channel when `ctx` is done, when Stop is
+// called, or when `expr` has no more matching time instant.
+func NewTicker(ctx context.Context, expr *Expression) *Ticker {
+	return NewTickerWithClock(ctx, expr, RealClock{})
+}
+
+// NewTickerWithClock is like NewTicker but tells the time using `clock`.
+func NewTickerWithClock(ctx context.Context, expr *Expression, clock Clock) *Ticker {
+	c := make(chan time.Time, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	t := &Ticker{C: c, cancel: cancel, done: make(chan struct{})}
+	next := expr.Next(clock.Now().Round(0))
+	go func() {
+		defer close(t.done)
+		runTicker(ctx, expr, clock, next, c, false)
+	}()
+	return t
+}
+
+// Stop turns off the ticker and closes its channel. A tick already pending in
+// the channel can still be received.
+func (t *Ticker) Stop() {
+	t.cancel()
+	<-t.done
+}
+
+/******************************************************************************/
+
+// A Timer holds a channel which delivers the next time instant matched by an
+// expression, once.
+type Timer struct {
+	// C receives the matched time instant. It is closed once the timer fires
+	// or stops, see NewTimer.
+	C      <-chan time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTimer returns a new Timer delivering the next time instant matched by
+// `expr`, then closing its channel. The caller remains free to use `expr`
+// meanwhile.
+//
+// The channel is closed without delivering anything if `ctx` is done or Stop
+// is called beforehand, or if `expr` has no more matching time instant.
+func NewTimer(ctx context.Context, expr *Expression) *Timer {
+	return NewTimerWithClock(ctx, expr, RealClock{})
+}
+
+// NewTimerWithClock is like NewTimer but tells the time using `clock`.
+func NewTimerWithClock(ctx context.Context, expr *Expression, clock Clock) *Timer {
+	c := make(chan time.Time, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	t := &Timer{C: c, cancel: cancel, done: make(chan struct{})}
+	next := expr.Next(clock.Now().Round(0))
+	go func() {
+		defer close(t.done)
+		runTicker(ctx, expr, clock, next, c, true)
+	}()
+	return t
+}
+
+// Stop prevents the timer from firing, if it has not already, and closes its
+// channel.
+func (t *Timer) Stop() {
+	t.cancel()
+	<-t.done
+}
+
+/******************************************************************************/
+
+// runTicker delivers to `c` the time instants matched by `expr` from `next` on.
+// Time instants must not carry a monotonic clock reading, so that waits are
+// computed from the wall clock.
+func runTicker(ctx context.Context, expr *Expression, clock Clock, next time.Time, c chan<- time.Time, once bool) {
+	defer close(c)
+
+	for !next.IsZero() {
+		wait := next.Sub(clock.Now().Round(0))
+		if wait > maxTickerWait {
+			wait = maxTickerWait
+		}
+		timer := clock.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		now := clock.Now().Round(0)
+		if now.Before(next) {
+			// Not there yet, the wall clock may have been set back meanwhile
+			next = expr.Next(now)
+			continue
+		}
+		select {
+		case c <- next:
+		default:
+		}
+		if once {
+			return
+		}
+		next = expr.Next(now)
+	}
+}
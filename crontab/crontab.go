@@ -0,0 +1,266 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: crontab/crontab.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+// Package crontab parses crontab files, as read by cron(8), into entries whose
+// schedules are cronexpr expressions.
+package crontab
+
+/******************************************************************************/
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// An Entry is a single job line of a crontab file.
+type Entry struct {
+	// Line is the 1-based line number of the entry in the crontab file.
+	Line int
+	// Spec is the schedule part of the line as written, i.e. the five time
+	// fields or the `@` macro.
+	Spec string
+	// Schedule is the parsed schedule.
+	Schedule *cronexpr.Expression
+	// Reboot is true for `@reboot` entries, which run once at cron startup.
+	// It is a shorthand for Schedule.IsReboot().
+	Reboot bool
+	// User is the user the command runs as. It is only set for system
+	// crontabs (see ParseSystem).
+	User string
+	// Command is the remainder of the line, verbatim.
+	Command string
+	// Env holds the environment assignments in effect for this entry, that
+	// is, those which appear before it in the file.
+	Env map[string]string
+}
+
+// A Variable is an environment assignment line of a crontab file.
+type Variable struct {
+	Line  int
+	Name  string
+	Value string
+}
+
+// A Crontab is the parsed content of a crontab file.
+type Crontab struct {
+	Env     []Variable
+	Entries []Entry
+}
+
+// A ParseError reports a malformed crontab line.
+type ParseError struct {
+	Line int
+	// Column is the 1-based byte column of the offending token in the line,
+	// or of the end of the line if something is missing.
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+/******************************************************************************/
+
+var (
+	envFinder   = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+	everyFinder = regexp.MustCompile(`(?i)^@every\s`)
+)
+
+/******************************************************************************/
+
+// Parse reads a user crontab, as edited by crontab(1), from `r`. Each job line
+// holds five time fields (or an `@` macro) followed by the command.
+//
+// An error of type *ParseError is returned for the first malformed line.
+func Parse(r io.Reader) (*Crontab, error) {
+	return parse(r, false)
+}
+
+// ParseSystem reads a system crontab such as /etc/crontab or a file of
+// /etc/cron.d from `r`. These have a user column between the time fields and
+// the command.
+//
+// An error of type *ParseError is returned for the first malformed line.
+func ParseSystem(r io.Reader) (*Crontab, error) {
+	return parse(r, true)
+}
+
+// ParseAll is like Parse, but it goes on past malformed lines: it returns the
+// entries of the well-formed lines along with a *ParseError for each
+// malformed one. The returned error is only about reading from `r`.
+func ParseAll(r io.Reader) (*Crontab, []*ParseError, error) {
+	return parseAll(r, false)
+}
+
+// ParseSystemAll is to ParseSystem what ParseAll is to Parse.
+func ParseSystemAll(r io.Reader) (*Crontab, []*ParseError, error) {
+	return parseAll(r, true)
+}
+
+/******************************************************************************/
+
+func parse(r io.Reader, system bool) (*Crontab, error) {
+	tab, errs, err := parseAll(r, system)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return tab, nil
+}
+
+func parseAll(r io.Reader, system bool) (*Crontab, []*ParseError, error) {
+	tab := &Crontab{}
+	env := make(map[string]string)
+	var errs []*ParseError
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo += 1
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		indent := strings.Index(raw, line)
+
+		// `NAME=value`
+		if matches := envFinder.FindStringSubmatch(line); matches != nil {
+			value := unquote(strings.TrimSpace(matches[2]))
+			env[matches[1]] = value
+			tab.Env = append(tab.Env, Variable{Line: lineNo, Name: matches[1], Value: value})
+			continue
+		}
+
+		entry, column, err := parseEntry(line, system)
+		if err != nil {
+			errs = append(errs, &ParseError{Line: lineNo, Column: indent + column + 1, Err: err})
+			continue
+		}
+		entry.Line = lineNo
+		entry.Env = make(map[string]string, len(env))
+		for k, v := range env {
+			entry.Env[k] = v
+		}
+		tab.Entries = append(tab.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return tab, errs, nil
+}
+
+// parseEntry parses a job line. On error, it also returns the 0-based column
+// of the offending token in `line`.
+func parseEntry(line string, system bool) (Entry, int, error) {
+	var entry Entry
+
+	// `@daily`, `@reboot`, `@every 1h`, ...
+	scheduleFieldCount := 5
+	if everyFinder.MatchString(line) {
+		scheduleFieldCount = 2
+	} else if line[0] == '@' {
+		scheduleFieldCount = 1
+	}
+	wanted := scheduleFieldCount
+	if system {
+		wanted += 1
+	}
+	fields, starts, rest := splitFields(line, wanted)
+	if len(fields) < scheduleFieldCount {
+		return entry, len(line), fmt.Errorf("missing time field(s)")
+	}
+	if system && len(fields) < wanted {
+		return entry, len(line), fmt.Errorf("missing user field")
+	}
+	if rest == "" {
+		return entry, len(line), fmt.Errorf("missing command")
+	}
+
+	entry.Spec = strings.Join(fields[:scheduleFieldCount], " ")
+	if system {
+		entry.User = fields[scheduleFieldCount]
+	}
+	entry.Command = rest
+
+	expr, err := cronexpr.Parse(entry.Spec)
+	if err != nil {
+		return entry, specColumn(err, fields[:scheduleFieldCount], starts), err
+	}
+	entry.Schedule = expr
+	entry.Reboot = expr.IsReboot()
+	return entry, 0, nil
+}
+
+// specColumn returns the column, in the line, of the token at fault for
+// `err`, an error about the schedule made of `fields` found at `starts`.
+func specColumn(err error, fields []string, starts []int) int {
+	var serr *cronexpr.SyntaxError
+	if !errors.As(err, &serr) {
+		return starts[0]
+	}
+	// The schedule was parsed with its fields joined by single spaces
+	offset := serr.Offset
+	for i, field := range fields {
+		if offset <= len(field) || i == len(fields)-1 {
+			return starts[i] + offset
+		}
+		offset -= len(field) + 1
+	}
+	return starts[0]
+}
+
+// splitFields returns at most `n` leading whitespace-separated fields of `s`
+// along with their offsets in `s` and the remainder of `s`, whose inner
+// spacing is left untouched.
+func splitFields(s string, n int) ([]string, []int, string) {
+	fields := make([]string, 0, n)
+	starts := make([]int, 0, n)
+	offset := 0
+	for len(fields) < n {
+		trimmed := strings.TrimLeft(s, " \t")
+		offset += len(s) - len(trimmed)
+		s = trimmed
+		if s == "" {
+			break
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		fields = append(fields, s[:end])
+		starts = append(starts, offset)
+		s = s[end:]
+		offset += end
+	}
+	return fields, starts, strings.TrimLeft(s, " \t")
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: crontab/crontab_test.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package crontab
+
+/******************************************************************************/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/******************************************************************************/
+
+const systemCrontab = `
+# /etc/crontab: system-wide crontab
+SHELL=/bin/sh
+PATH = "/usr/local/sbin:/usr/local/bin:/sbin:/bin"
+
+# m h dom mon dow user	command
+17 *	* * *	root    cd / && run-parts --report /etc/cron.hourly
+25 6	* * *	root	test -x /usr/sbin/anacron || ( cd / && run-parts --report /etc/cron.daily )
+MAILTO=ops@example.com
+@reboot		root	/usr/local/bin/warmup
+@weekly		backup	/usr/local/bin/backup  --full
+`
+
+func TestParseSystem(t *testing.T) {
+	tab, err := ParseSystem(strings.NewReader(systemCrontab))
+	require.NoError(t, err)
+
+	require.Len(t, tab.Env, 3)
+	assert.Equal(t, Variable{Line: 3, Name: "SHELL", Value: "/bin/sh"}, tab.Env[0])
+	assert.Equal(t, Variable{Line: 4, Name: "PATH", Value: "/usr/local/sbin:/usr/local/bin:/sbin:/bin"}, tab.Env[1])
+	assert.Equal(t, Variable{Line: 9, Name: "MAILTO", Value: "ops@example.com"}, tab.Env[2])
+
+	require.Len(t, tab.Entries, 4)
+
+	hourly := tab.Entries[0]
+	assert.Equal(t, 7, hourly.Line)
+	assert.Equal(t, "17 * * * *", hourly.Spec)
+	assert.Equal(t, "root", hourly.User)
+	assert.Equal(t, "cd / && run-parts --report /etc/cron.hourly", hourly.Command)
+	assert.NotContains(t, hourly.Env, "MAILTO")
+	from := time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2013, time.January, 1, 0, 17, 0, 0, time.UTC), hourly.Schedule.Next(from))
+
+	reboot := tab.Entries[2]
+	assert.True(t, reboot.Reboot)
+	assert.True(t, reboot.Schedule.IsReboot())
+	assert.Equal(t, "/usr/local/bin/warmup", reboot.Command)
+	assert.Equal(t, "ops@example.com", reboot.Env["MAILTO"])
+
+	weekly := tab.Entries[3]
+	assert.Equal(t, "backup", weekly.User)
+	assert.Equal(t, "/usr/local/bin/backup  --full", weekly.Command)
+	assert.Equal(t, time.Date(2013, time.January, 6, 0, 0, 0, 0, time.UTC), weekly.Schedule.Next(from))
+}
+
+func TestParseUser(t *testing.T) {
+	tab, err := Parse(strings.NewReader("*/5 9-17 * * mon-fri  echo hello %world\n@every 90m date\n@REBOOT uptime\n"))
+	require.NoError(t, err)
+	require.Len(t, tab.Entries, 3)
+	assert.Equal(t, "", tab.Entries[0].User)
+	assert.Equal(t, "echo hello %world", tab.Entries[0].Command)
+	assert.Equal(t, "@every 90m", tab.Entries[1].Spec)
+	assert.Equal(t, 90*time.Minute, tab.Entries[1].Schedule.Every())
+	assert.Equal(t, "date", tab.Entries[1].Command)
+	assert.True(t, tab.Entries[2].Reboot)
+	assert.Equal(t, "uptime", tab.Entries[2].Command)
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		system bool
+		input  string
+		line   int
+		column int
+	}{
+		{"missing fields", false, "FOO=bar\n* * *\n", 2, 6},
+		{"missing command", false, "\n\n0 0 * * *\n", 3, 10},
+		{"missing user", true, "0 0 * * * \n", 1, 10},
+		{"bad schedule", false, "# comment\n61 * * * * true\n", 2, 1},
+		{"bad field", false, "  0  0\t1,xx * *  true\n", 1, 10},
+		{"bad interval", false, "@every  1x true\n", 1, 9},
+		{"unknown alias", false, "@everyday 1h true\n", 1, 10},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var err error
+			if c.system {
+				_, err = ParseSystem(strings.NewReader(c.input))
+			} else {
+				_, err = Parse(strings.NewReader(c.input))
+			}
+			var perr *ParseError
+			require.True(t, errors.As(err, &perr), "expected *ParseError, got %v", err)
+			assert.Equal(t, c.line, perr.Line)
+			assert.Equal(t, c.column, perr.Column)
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	input := "0 0 * * * ok\n61 * * * * bad\n@hourly ok\n* * *\n"
+	tab, errs, err := ParseAll(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, tab.Entries, 2)
+	assert.Equal(t, 1, tab.Entries[0].Line)
+	assert.Equal(t, 3, tab.Entries[1].Line)
+	require.Len(t, errs, 2)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Equal(t, 4, errs[1].Line)
+	assert.Equal(t, "line 4, column 6: missing time field(s)", errs[1].Error())
+}
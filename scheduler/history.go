@@ -0,0 +1,124 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/history.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"fmt"
+	"time"
+)
+
+/******************************************************************************/
+
+const defaultHistorySize = 100
+
+/******************************************************************************/
+
+// An Outcome tells how a run of a job ended.
+type Outcome int
+
+const (
+	// Succeeded means the run returned no error.
+	Succeeded Outcome = iota
+	// Failed means the run returned an error or panicked, or its lock could
+	// not be acquired.
+	Failed
+	// Skipped means the run was dropped because a previous one was still in
+	// progress, see Skip.
+	Skipped
+	// Lost means the lock of the run was won by another scheduler, see
+	// Options.Locker.
+	Lost
+	// Canceled means the scheduler stopped before the run could start.
+	Canceled
+)
+
+var outcomeNames = map[Outcome]string{
+	Succeeded: "succeeded",
+	Failed:    "failed",
+	Skipped:   "skipped",
+	Lost:      "lost",
+	Canceled:  "canceled",
+}
+
+func (outcome Outcome) String() string {
+	if name, found := outcomeNames[outcome]; found {
+		return name
+	}
+	return fmt.Sprintf("Outcome(%d)", int(outcome))
+}
+
+// A RunRecord describes a run of a job, see Scheduler.History.
+type RunRecord struct {
+	Job string
+	// Scheduled is the time instant the run was for.
+	Scheduled time.Time
+	// Start and End are when the job func was called and when it returned,
+	// the zero value if it was not called.
+	Start   time.Time
+	End     time.Time
+	Outcome Outcome
+	// Err is the error of a failed or canceled run.
+	Err error
+	// Owner is the identity of the scheduler which ran the job, or which won
+	// its lock if the run was lost, see Options.Identity.
+	Owner string
+}
+
+// Duration returns how long the job func ran.
+func (record RunRecord) Duration() time.Duration {
+	return record.End.Sub(record.Start)
+}
+
+// A RecordSink receives the record of each run of a scheduler as it ends, to
+// keep them beyond the history of the scheduler, see Options.Sink.
+type RecordSink interface {
+	Record(record RunRecord)
+}
+
+/******************************************************************************/
+
+// History returns the records of the last runs of the job named `name`, oldest
+// first, and reports whether there is such a job. At most Options.HistorySize
+// records are kept per job.
+func (s *Scheduler) History(name string) ([]RunRecord, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, found := s.entries[name]
+	if !found {
+		return nil, false
+	}
+	return append([]RunRecord(nil), e.history...), true
+}
+
+// record adds `record` to the history of `e` and passes it to the sink, if
+// any. The scheduler lock must not be held.
+func (s *Scheduler) record(e *entry, record RunRecord) {
+	size := s.opts.HistorySize
+	if size == 0 {
+		size = defaultHistorySize
+	}
+
+	s.lock.Lock()
+	if size > 0 {
+		if len(e.history) == size {
+			e.history = append(e.history[:0], e.history[1:]...)
+		}
+		e.history = append(e.history, record)
+	}
+	s.lock.Unlock()
+
+	if s.opts.Sink != nil {
+		s.opts.Sink.Record(record)
+	}
+}
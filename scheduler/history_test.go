@@ -0,0 +1,113 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/history_test.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+type chanSink chan RunRecord
+
+func (sink chanSink) Record(record RunRecord) {
+	sink <- record
+}
+
+func TestSchedulerHistory(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base.Add(-time.Second))
+	sink := make(chanSink, 10)
+	s := New(Options{Clock: clock, Identity: "here", HistorySize: 2, Sink: sink})
+	errOdd := errors.New("odd hour")
+	require.NoError(t, s.Add(Job{
+		Name:     "hourly",
+		Schedule: cronexpr.MustParse("0 * * * *"),
+		Func: func(ctx context.Context, scheduled time.Time) error {
+			if scheduled.Hour()%2 == 1 {
+				return errOdd
+			}
+			return nil
+		},
+	}))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		record := <-sink
+		assert.Equal(t, base.Add(time.Duration(i)*time.Hour), record.Scheduled)
+		clock.Advance(time.Hour - time.Second)
+	}
+
+	history, found := s.History("hourly")
+	require.True(t, found)
+	require.Len(t, history, 2)
+	assert.Equal(t, RunRecord{
+		Job:       "hourly",
+		Scheduled: base.Add(time.Hour),
+		Start:     base.Add(time.Hour),
+		End:       base.Add(time.Hour),
+		Outcome:   Failed,
+		Err:       errOdd,
+		Owner:     "here",
+	}, history[0])
+	assert.Equal(t, base.Add(2*time.Hour), history[1].Scheduled)
+	assert.Equal(t, Succeeded, history[1].Outcome)
+	assert.Zero(t, history[1].Duration())
+
+	_, found = s.History("missing")
+	assert.False(t, found)
+}
+
+func TestSchedulerHistorySkipped(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base.Add(-time.Second))
+	sink := make(chanSink, 10)
+	s := New(Options{Clock: clock, Sink: sink})
+	release := make(chan struct{})
+	require.NoError(t, s.Add(Job{
+		Name:        "slow",
+		Schedule:    cronexpr.MustParse("0 * * * *"),
+		Concurrency: Skip,
+		Func: func(ctx context.Context, scheduled time.Time) error {
+			<-release
+			return nil
+		},
+	}))
+	s.Start(context.Background())
+
+	clock.Advance(time.Second)
+	assert.Eventually(t, func() bool {
+		info, _ := s.Job("slow")
+		return info.Running == 1
+	}, time.Second, 10*time.Millisecond)
+	clock.Advance(time.Hour)
+	record := <-sink
+	assert.Equal(t, Skipped, record.Outcome)
+	assert.Equal(t, base.Add(time.Hour), record.Scheduled)
+	assert.True(t, record.Start.IsZero())
+
+	close(release)
+	record = <-sink
+	assert.Equal(t, Succeeded, record.Outcome)
+	s.Stop()
+}
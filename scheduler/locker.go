@@ -0,0 +1,152 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/locker.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/******************************************************************************/
+
+// A Slot is a run of a job: its name and the time instant it is scheduled
+// for.
+type Slot struct {
+	Job       string
+	Scheduled time.Time
+}
+
+// A Locker decides which of several schedulers runs each slot, so that a job
+// shared by replicas runs once per time instant, see Options.Locker.
+// Implementations must be safe for concurrent use.
+type Locker interface {
+	// Lock claims `slot` on behalf of `owner`. It returns the owner of the
+	// slot, which is `owner` if the claim succeeded, and reports whether it
+	// did. A slot is claimed once and for all: once its owner is done with
+	// it, later claims still fail, and so do claims of earlier slots of the
+	// same job.
+	Lock(slot Slot, owner string) (string, bool, error)
+}
+
+// A lockRecord is the last slot claimed for a job.
+type lockRecord struct {
+	Scheduled time.Time `json:"scheduled"`
+	Owner     string    `json:"owner"`
+}
+
+// claim claims `slot` for `owner` if it is later than the slot of `record`,
+// and returns the resulting record.
+func (record lockRecord) claim(slot Slot, owner string) (lockRecord, bool) {
+	if !slot.Scheduled.After(record.Scheduled) {
+		return record, record.Owner == owner && slot.Scheduled.Equal(record.Scheduled)
+	}
+	return lockRecord{Scheduled: slot.Scheduled, Owner: owner}, true
+}
+
+/******************************************************************************/
+
+// A MemoryLocker is a Locker shared by the schedulers of the current process.
+type MemoryLocker struct {
+	lock    sync.Mutex
+	records map[string]lockRecord
+}
+
+// NewMemoryLocker returns a new MemoryLocker, with no slot claimed.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{records: make(map[string]lockRecord)}
+}
+
+func (locker *MemoryLocker) Lock(slot Slot, owner string) (string, bool, error) {
+	locker.lock.Lock()
+	defer locker.lock.Unlock()
+
+	record, acquired := locker.records[slot.Job].claim(slot, owner)
+	locker.records[slot.Job] = record
+	return record.Owner, acquired, nil
+}
+
+/******************************************************************************/
+
+// A FileLocker is a Locker shared by the processes of the current host. It
+// keeps the last slot claimed for each job in a file of its directory, which
+// it locks with flock(2) while claiming. File locking is only supported on
+// Unix systems.
+type FileLocker struct {
+	dir string
+}
+
+// NewFileLocker returns a new FileLocker keeping its files in the directory
+// `dir`, which must exist.
+func NewFileLocker(dir string) *FileLocker {
+	return &FileLocker{dir: dir}
+}
+
+func (locker *FileLocker) Lock(slot Slot, owner string) (string, bool, error) {
+	path := filepath.Join(locker.dir, url.PathEscape(slot.Job)+".lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return "", false, fmt.Errorf("%s: %w", path, err)
+	}
+	defer unlockFile(f)
+
+	var record lockRecord
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &record); err != nil {
+			return "", false, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	claimed, acquired := record.claim(slot, owner)
+	if claimed == record {
+		return record.Owner, acquired, nil
+	}
+
+	if data, err = json.Marshal(claimed); err != nil {
+		return "", false, err
+	}
+	if err := f.Truncate(0); err != nil {
+		return "", false, err
+	}
+	if _, err := f.WriteAt(append(data, '\n'), 0); err != nil {
+		return "", false, err
+	}
+	if err := f.Sync(); err != nil {
+		return "", false, err
+	}
+	return claimed.Owner, acquired, nil
+}
+
+/******************************************************************************/
+
+// defaultIdentity returns the identity of the current process, see
+// Options.Identity.
+func defaultIdentity() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
@@ -0,0 +1,31 @@
+//go:build !unix
+
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/locker_other.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+/******************************************************************************/
+
+func lockFile(f *os.File) error {
+	return fmt.Errorf("file locking not supported on %s", runtime.GOOS)
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
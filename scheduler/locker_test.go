@@ -0,0 +1,190 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/locker_test.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+func TestLockers(t *testing.T) {
+	dir := t.TempDir()
+	lockers := map[string]func() Locker{
+		"memory": func() Locker {
+			return NewMemoryLocker()
+		},
+		"file": func() Locker {
+			return NewFileLocker(dir)
+		},
+	}
+	base := time.Date(2013, time.January, 1, 2, 0, 0, 0, time.UTC)
+	for name, newLocker := range lockers {
+		t.Run(name, func(t *testing.T) {
+			locker := newLocker()
+			slot := Slot{Job: "backup/daily", Scheduled: base}
+			owner, acquired, err := locker.Lock(slot, "a")
+			require.NoError(t, err)
+			assert.True(t, acquired)
+			assert.Equal(t, "a", owner)
+
+			// Claimed once and for all
+			owner, acquired, err = locker.Lock(slot, "b")
+			require.NoError(t, err)
+			assert.False(t, acquired)
+			assert.Equal(t, "a", owner)
+			_, acquired, _ = locker.Lock(Slot{Job: "backup/daily", Scheduled: base.Add(-time.Hour)}, "b")
+			assert.False(t, acquired)
+
+			// Other slots and jobs are independent
+			owner, acquired, _ = locker.Lock(Slot{Job: "backup/daily", Scheduled: base.Add(time.Hour)}, "b")
+			assert.True(t, acquired)
+			assert.Equal(t, "b", owner)
+			_, acquired, _ = locker.Lock(Slot{Job: "cleanup", Scheduled: base}, "b")
+			assert.True(t, acquired)
+		})
+	}
+}
+
+func TestFileLockerContention(t *testing.T) {
+	dir := t.TempDir()
+	slot := Slot{Job: "backup", Scheduled: time.Date(2013, time.January, 1, 2, 0, 0, 0, time.UTC)}
+	var wg sync.WaitGroup
+	winners := make(chan string, 10)
+	for i := 0; i < cap(winners); i++ {
+		wg.Add(1)
+		go func(owner string) {
+			defer wg.Done()
+			// A locker per replica, as in separate processes
+			if _, acquired, err := NewFileLocker(dir).Lock(slot, owner); err == nil && acquired {
+				winners <- owner
+			}
+		}(fmt.Sprint("replica-", i))
+	}
+	wg.Wait()
+	close(winners)
+	assert.Len(t, winners, 1)
+}
+
+func TestSchedulerLocker(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base.Add(-time.Second))
+	locker := NewMemoryLocker()
+	ran := make(chan time.Time, 10)
+	var schedulers []*Scheduler
+	for _, identity := range []string{"a", "b", "c"} {
+		s := New(Options{Clock: clock, Locker: locker, Identity: identity})
+		require.NoError(t, s.Add(Job{
+			Name:     "hourly",
+			Schedule: cronexpr.MustParse("0 * * * *"),
+			Func: func(ctx context.Context, scheduled time.Time) error {
+				ran <- scheduled
+				return nil
+			},
+		}))
+		s.Start(context.Background())
+		schedulers = append(schedulers, s)
+	}
+
+	clock.Advance(time.Second)
+	assert.Equal(t, []time.Time{base}, collect(ran))
+	clock.Advance(time.Hour)
+	assert.Equal(t, []time.Time{base.Add(time.Hour)}, collect(ran))
+
+	info, _ := schedulers[0].Job("hourly")
+	assert.NotEmpty(t, info.Owner)
+	for _, s := range schedulers {
+		other, _ := s.Job("hourly")
+		assert.Equal(t, info.Owner, other.Owner)
+		s.Stop()
+	}
+}
+
+func TestSchedulerLockerWorkers(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base.Add(-time.Second))
+	locker := NewMemoryLocker()
+	s := New(Options{Clock: clock, Locker: locker, Identity: "a", Workers: 1})
+	started := make(chan string, 10)
+	for name, schedule := range map[string]string{"busy": "0 * * * *", "late": "1 * * * *"} {
+		name := name
+		require.NoError(t, s.Add(Job{
+			Name:     name,
+			Schedule: cronexpr.MustParse(schedule),
+			Func: func(ctx context.Context, scheduled time.Time) error {
+				started <- name
+				<-ctx.Done()
+				return nil
+			},
+		}))
+	}
+	s.Start(context.Background())
+	clock.Advance(time.Second)
+	assert.Equal(t, "busy", <-started)
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool {
+		info, _ := s.Job("late")
+		return info.Next.After(base.Add(time.Minute))
+	}, time.Second, 10*time.Millisecond)
+	s.Stop()
+
+	// The run canceled while waiting for a worker did not claim its slot
+	history, _ := s.History("late")
+	require.Len(t, history, 1)
+	assert.Equal(t, Canceled, history[0].Outcome)
+	_, acquired, err := locker.Lock(Slot{Job: "late", Scheduled: base.Add(time.Minute)}, "b")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestSchedulerLockerMisfire(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 30, 0, 0, time.UTC)
+	hour := func(h int) time.Time {
+		return time.Date(2013, time.January, 1, h, 0, 0, 0, time.UTC)
+	}
+	locker := NewMemoryLocker()
+	ran := make(chan time.Time, 20)
+	var schedulers []*Scheduler
+	for _, identity := range []string{"a", "b", "c"} {
+		s := New(Options{Clock: cronexpr.NewFakeClock(base), Locker: locker, Identity: identity})
+		require.NoError(t, s.Add(Job{
+			Name:     "hourly",
+			Schedule: cronexpr.MustParse("0 * * * *"),
+			Misfire:  MisfireRunAll,
+			LastRun:  hour(7),
+			Func: func(ctx context.Context, scheduled time.Time) error {
+				ran <- scheduled
+				return nil
+			},
+		}))
+		schedulers = append(schedulers, s)
+	}
+	for _, s := range schedulers {
+		s.Start(context.Background())
+	}
+
+	// Each missed run is caught up with once, by any of the schedulers
+	assert.Equal(t, []time.Time{hour(8), hour(9), hour(10), hour(11), hour(12)}, collect(ran))
+	for _, s := range schedulers {
+		s.Stop()
+	}
+}
@@ -0,0 +1,648 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/scheduler.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+// Package scheduler runs named jobs at the time instants matched by cronexpr
+// expressions, within the current process.
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+// maxWait bounds how long the scheduler sleeps at once, so that it notices
+// when the wall clock is set forward or back.
+const maxWait = 30 * time.Second
+
+const (
+	defaultMisfireThreshold = time.Second
+	defaultMaxMisfires      = 100
+	defaultMaxPending       = 100
+)
+
+/******************************************************************************/
+
+// A Func is the work of a job. It is passed the time instant for which it is
+// run, and a context which is canceled when the scheduler stops.
+type Func func(ctx context.Context, scheduled time.Time) error
+
+// A Concurrency tells what to do when a job is due while a previous run of it
+// is still in progress.
+type Concurrency int
+
+const (
+	// Allow starts the new run alongside the previous one.
+	Allow Concurrency = iota
+	// Skip drops the new run.
+	Skip
+	// Queue starts the new run once the previous one completes, up to
+	// Job.MaxPending runs waiting at once. Further runs are skipped.
+	Queue
+)
+
+// A Misfire tells what to do with the runs of a job which were missed, because
+// the process was not running or the machine was asleep at the time.
+type Misfire int
+
+const (
+	// MisfireRunOnce runs the job once, for the most recent missed time
+	// instant.
+	MisfireRunOnce Misfire = iota
+	// MisfireSkip drops the missed runs, the job runs next at the next
+	// matching time instant.
+	MisfireSkip
+	// MisfireRunAll runs the job for each missed time instant, oldest first,
+	// up to the Job.MaxMisfires most recent ones.
+	MisfireRunAll
+)
+
+// A Job is a named piece of work run at the time instants matched by its
+// schedule.
+type Job struct {
+	Name string
+	// Schedule may be shared with other jobs and schedulers.
+	Schedule    *cronexpr.Expression
+	Func        Func
+	Concurrency Concurrency
+	Misfire     Misfire
+	// MaxMisfires bounds the number of missed runs caught up with by
+	// MisfireRunAll, the most recent ones being kept. Zero means 100, a
+	// negative value means no limit.
+	MaxMisfires int
+	// MaxPending bounds the number of runs waiting for a previous one to
+	// complete with Queue. Zero means 100.
+	MaxPending int
+	// LastRun, if not zero, is the time instant of the last run of the job,
+	// typically in a previous process. The runs missed since then are
+	// subject to the misfire policy once the scheduler is started.
+	LastRun time.Time
+}
+
+// Options configure a Scheduler. The zero value is usable.
+type Options struct {
+	// Workers is the maximum number of runs in progress at once, all jobs
+	// included. Zero means no limit, that is, a goroutine per run.
+	Workers int
+	// ErrorHandler, if not nil, is called with the name of the job and the
+	// error returned by each failed run. Panics are recovered and reported
+	// as errors.
+	ErrorHandler func(name string, err error)
+	// MisfireThreshold is how late a run may start before it is considered
+	// missed, see Misfire. Zero means one second.
+	MisfireThreshold time.Duration
+	// Clock tells the time to the scheduler. Nil means cronexpr.RealClock.
+	Clock cronexpr.Clock
+	// Store, if not nil, records the state of jobs as they run. A job added
+	// without LastRun resumes from the last run recorded in the store.
+	// Errors of the store are reported to ErrorHandler.
+	Store JobStore
+	// Locker, if not nil, is asked for the lock of each run before it starts,
+	// so that schedulers sharing it run each time instant of a job once. Runs
+	// whose lock is held by another scheduler are dropped.
+	Locker Locker
+	// Identity names the scheduler to the Locker. Empty means the host name
+	// and process ID, e.g. "host:1234".
+	Identity string
+	// HistorySize is the number of run records kept per job, see History.
+	// Zero means 100, a negative value means none.
+	HistorySize int
+	// Sink, if not nil, receives the record of each run as it ends.
+	Sink RecordSink
+}
+
+// A JobInfo describes the state of a job, see Scheduler.Jobs.
+type JobInfo struct {
+	Name string
+	// Next is the next time instant the job is due, or the zero value if its
+	// schedule has no more matching time instant.
+	Next time.Time
+	// Running is the number of runs of the job in progress.
+	Running int
+	// Pending is the number of runs of the job waiting for a previous one to
+	// complete (see Queue).
+	Pending int
+	// Owner is the identity of the scheduler which won the lock of the last
+	// run of the job, if the scheduler has a Locker.
+	Owner string
+}
+
+/******************************************************************************/
+
+// A Scheduler runs jobs at the time instants matched by their schedule. It is
+// safe for concurrent use.
+type Scheduler struct {
+	opts     Options
+	clock    cronexpr.Clock
+	identity string
+	lock     sync.Mutex
+	saving   sync.Mutex
+	entries  map[string]*entry
+	queue    entryQueue
+	wake     chan struct{}
+	workers  chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	runs     sync.WaitGroup
+}
+
+type entry struct {
+	job     Job
+	next    time.Time
+	index   int
+	running int
+	pending []time.Time
+	lastRun time.Time
+	owner   string
+	history []RunRecord
+	// claimed is closed once the last run dispatched has claimed its slot
+	// from the Locker, if any.
+	claimed chan struct{}
+}
+
+// New returns a new, stopped, Scheduler.
+func New(opts Options) *Scheduler {
+	s := &Scheduler{
+		opts:    opts,
+		clock:   opts.Clock,
+		entries: make(map[string]*entry),
+		wake:    make(chan struct{}, 1),
+	}
+	if s.clock == nil {
+		s.clock = cronexpr.RealClock{}
+	}
+	s.identity = opts.Identity
+	if s.identity == "" {
+		s.identity = defaultIdentity()
+	}
+	if opts.Workers > 0 {
+		s.workers = make(chan struct{}, opts.Workers)
+	}
+	return s
+}
+
+/******************************************************************************/
+
+// Add registers `job`, which is scheduled from now on, or from its last run if
+// known. An error is returned if the job is incomplete or if a job of the same
+// name is already registered.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job has no name")
+	}
+	if job.Schedule == nil {
+		return fmt.Errorf("job %s has no schedule", job.Name)
+	}
+	if job.Func == nil {
+		return fmt.Errorf("job %s has no func", job.Name)
+	}
+
+	if job.LastRun.IsZero() && s.opts.Store != nil {
+		state, found, err := s.opts.Store.Load(job.Name)
+		if err != nil {
+			return fmt.Errorf("job %s: loading state: %w", job.Name, err)
+		}
+		if found {
+			job.LastRun = state.LastRun
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, found := s.entries[job.Name]; found {
+		return fmt.Errorf("job %s already registered", job.Name)
+	}
+	e := &entry{job: job, index: -1, lastRun: job.LastRun}
+	if job.LastRun.IsZero() {
+		e.next = job.Schedule.Next(s.now())
+	} else {
+		e.next = job.Schedule.Next(job.LastRun)
+	}
+	s.entries[job.Name] = e
+	if !e.next.IsZero() {
+		heap.Push(&s.queue, e)
+	}
+	s.notify()
+	return nil
+}
+
+// Remove unregisters the job named `name`, and reports whether there was such
+// a job. Runs of the job in progress are not interrupted, but its pending
+// runs are dropped.
+func (s *Scheduler) Remove(name string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, found := s.entries[name]
+	if !found {
+		return false
+	}
+	delete(s.entries, name)
+	if e.index >= 0 {
+		heap.Remove(&s.queue, e.index)
+	}
+	e.pending = nil
+	s.notify()
+	return true
+}
+
+// Jobs returns the state of the registered jobs, sorted by name.
+func (s *Scheduler) Jobs() []JobInfo {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	infos := make([]JobInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		infos = append(infos, e.info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// Job returns the state of the job named `name`, and reports whether there is
+// such a job.
+func (s *Scheduler) Job(name string) (JobInfo, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, found := s.entries[name]
+	if !found {
+		return JobInfo{}, false
+	}
+	return e.info(), true
+}
+
+func (e *entry) info() JobInfo {
+	return JobInfo{
+		Name:    e.job.Name,
+		Next:    e.next,
+		Running: e.running,
+		Pending: len(e.pending),
+		Owner:   e.owner,
+	}
+}
+
+/******************************************************************************/
+
+// Start starts running jobs in the background, until `ctx` is done or Stop is
+// called. The context of each run derives from `ctx`. Start does nothing if
+// the scheduler is already started.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.done != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.loop(s.ctx, s.done)
+}
+
+// Stop stops running jobs, cancels the context of the runs in progress and
+// waits for them to return. Pending runs are dropped, and recorded as
+// canceled. The scheduler can be started again afterwards.
+func (s *Scheduler) Stop() {
+	s.lock.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.lock.Unlock()
+
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+	s.runs.Wait()
+	s.dropPending()
+}
+
+// dropPending drops the runs waiting for a previous one to complete, once the
+// scheduler is stopped, and records them as canceled.
+func (s *Scheduler) dropPending() {
+	type drop struct {
+		e      *entry
+		record RunRecord
+	}
+	var drops []drop
+	s.lock.Lock()
+	for _, e := range s.entries {
+		for _, scheduled := range e.pending {
+			drops = append(drops, drop{e, RunRecord{Job: e.job.Name, Scheduled: scheduled, Outcome: Canceled, Err: context.Canceled, Owner: s.identity}})
+		}
+		e.pending = nil
+	}
+	s.lock.Unlock()
+
+	for _, d := range drops {
+		s.record(d.e, d.record)
+	}
+}
+
+/******************************************************************************/
+
+func (s *Scheduler) loop(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		s.lock.Lock()
+		t := s.now()
+		for len(s.queue) > 0 && !s.queue[0].next.After(t) {
+			e := s.queue[0]
+			due := s.due(e, t)
+			e.next = e.job.Schedule.Next(t)
+			if e.next.IsZero() {
+				heap.Pop(&s.queue)
+			} else {
+				heap.Fix(&s.queue, 0)
+			}
+			for _, scheduled := range due {
+				s.dispatch(ctx, e, scheduled)
+			}
+		}
+		wait := maxWait
+		if len(s.queue) > 0 && s.queue[0].next.Sub(t) < wait {
+			wait = s.queue[0].next.Sub(t)
+		}
+		s.lock.Unlock()
+
+		timer := s.clock.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C():
+		}
+	}
+}
+
+// due returns the time instants for which `e` is to run at `t`, as decided by
+// its misfire policy if it is late. The scheduler lock must be held.
+func (s *Scheduler) due(e *entry, t time.Time) []time.Time {
+	threshold := s.opts.MisfireThreshold
+	if threshold == 0 {
+		threshold = defaultMisfireThreshold
+	}
+	scheduled := e.next
+	if t.Sub(scheduled) <= threshold {
+		return []time.Time{scheduled}
+	}
+
+	switch e.job.Misfire {
+	case MisfireRunOnce:
+		return []time.Time{e.job.Schedule.Prev(t.Add(time.Nanosecond))}
+	case MisfireRunAll:
+		maxMisfires := e.job.MaxMisfires
+		if maxMisfires == 0 {
+			maxMisfires = defaultMaxMisfires
+		}
+		// Walk back from `t`, so that only the kept runs are ever computed
+		var missed []time.Time
+		last := e.job.Schedule.Prev(t.Add(time.Nanosecond))
+		for ; !last.IsZero() && !last.Before(scheduled); last = e.job.Schedule.Prev(last) {
+			if len(missed) == maxMisfires {
+				break
+			}
+			missed = append(missed, last)
+		}
+		for i, j := 0, len(missed)-1; i < j; i, j = i+1, j-1 {
+			missed[i], missed[j] = missed[j], missed[i]
+		}
+		return missed
+	}
+	return nil
+}
+
+// dispatch starts a run of `e` for `scheduled` as allowed by the concurrency
+// policy of the job. The scheduler lock must be held.
+func (s *Scheduler) dispatch(ctx context.Context, e *entry, scheduled time.Time) {
+	if e.running > 0 {
+		switch e.job.Concurrency {
+		case Skip:
+			s.skip(e, scheduled)
+			return
+		case Queue:
+			maxPending := e.job.MaxPending
+			if maxPending == 0 {
+				maxPending = defaultMaxPending
+			}
+			if len(e.pending) < maxPending {
+				e.pending = append(e.pending, scheduled)
+			} else {
+				s.skip(e, scheduled)
+			}
+			return
+		}
+	}
+	e.running += 1
+	s.runs.Add(1)
+	var turn, claimed chan struct{}
+	if s.opts.Locker != nil {
+		// The Locker rejects slots earlier than the last one claimed, so that
+		// runs claim theirs in the order they are dispatched
+		turn, claimed = e.claimed, make(chan struct{})
+		e.claimed = claimed
+	}
+	go s.run(ctx, e, scheduled, turn, claimed)
+}
+
+// skip records the run of `e` for `scheduled` as skipped. The scheduler lock
+// must be held.
+func (s *Scheduler) skip(e *entry, scheduled time.Time) {
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		s.record(e, RunRecord{Job: e.job.Name, Scheduled: scheduled, Outcome: Skipped, Owner: s.identity})
+	}()
+}
+
+// run runs `e` for `scheduled`. With a Locker, it claims its slot once `turn`,
+// if not nil, is closed, and closes `claimed` once it is done claiming.
+func (s *Scheduler) run(ctx context.Context, e *entry, scheduled time.Time, turn <-chan struct{}, claimed chan<- struct{}) {
+	defer s.runs.Done()
+	release := func() {
+		if claimed != nil {
+			close(claimed)
+			claimed = nil
+		}
+	}
+	defer release()
+
+	record := RunRecord{Job: e.job.Name, Scheduled: scheduled, Owner: s.identity}
+	if turn != nil {
+		select {
+		case <-turn:
+		case <-ctx.Done():
+			record.Outcome, record.Err = Canceled, ctx.Err()
+			s.complete(ctx, e, record)
+			return
+		}
+	}
+	// Wait for a worker before claiming the slot, so that a run canceled
+	// meanwhile leaves it to other schedulers
+	if s.workers != nil {
+		select {
+		case s.workers <- struct{}{}:
+			defer func() { <-s.workers }()
+		case <-ctx.Done():
+			record.Outcome, record.Err = Canceled, ctx.Err()
+			s.complete(ctx, e, record)
+			return
+		}
+	}
+	if s.opts.Locker != nil {
+		owner, acquired, err := s.opts.Locker.Lock(Slot{Job: e.job.Name, Scheduled: scheduled}, s.identity)
+		release()
+		if err != nil {
+			record.Outcome, record.Err = Failed, fmt.Errorf("locking run: %w", err)
+			s.complete(ctx, e, record)
+			return
+		}
+		s.lock.Lock()
+		e.owner = owner
+		s.lock.Unlock()
+		if !acquired {
+			record.Outcome, record.Owner = Lost, owner
+			s.complete(ctx, e, record)
+			return
+		}
+	}
+	s.save(e, scheduled, StatusRunning)
+	record.Start = s.now()
+	record.Err = call(ctx, e.job.Func, scheduled)
+	record.End = s.now()
+	if record.Err != nil {
+		record.Outcome = Failed
+		s.save(e, scheduled, StatusFailed)
+	} else {
+		record.Outcome = Succeeded
+		s.save(e, scheduled, StatusSucceeded)
+	}
+	s.complete(ctx, e, record)
+}
+
+// complete accounts for the end of a run of `e` described by `record`, and
+// starts the next pending run of `e`, if any.
+func (s *Scheduler) complete(ctx context.Context, e *entry, record RunRecord) {
+	if record.Err != nil && s.opts.ErrorHandler != nil {
+		s.opts.ErrorHandler(e.job.Name, record.Err)
+	}
+	s.record(e, record)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e.running -= 1
+	if len(e.pending) > 0 && ctx.Err() == nil {
+		scheduled := e.pending[0]
+		e.pending = e.pending[1:]
+		s.dispatch(ctx, e, scheduled)
+	}
+}
+
+// save records the state of `e` in the store, if any, as of a run for
+// `scheduled` with `status`. Runs older than the last one started are not
+// recorded.
+func (s *Scheduler) save(e *entry, scheduled time.Time, status Status) {
+	if s.opts.Store == nil {
+		return
+	}
+	s.saving.Lock()
+	defer s.saving.Unlock()
+
+	s.lock.Lock()
+	if scheduled.Before(e.lastRun) {
+		s.lock.Unlock()
+		return
+	}
+	e.lastRun = scheduled
+	state := JobState{Name: e.job.Name, LastRun: scheduled, NextRun: e.next, Status: status, Owner: e.owner}
+	s.lock.Unlock()
+
+	err := s.opts.Store.Save(state)
+	if err != nil && s.opts.ErrorHandler != nil {
+		s.opts.ErrorHandler(e.job.Name, fmt.Errorf("saving state: %w", err))
+	}
+}
+
+func call(ctx context.Context, fn Func, scheduled time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, scheduled)
+}
+
+// notify wakes the scheduling loop up so that it accounts for changes to the
+// queue.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+/******************************************************************************/
+
+// now returns the current time without monotonic clock reading, so that
+// matched time instants are compared with the wall clock.
+func (s *Scheduler) now() time.Time {
+	return s.clock.Now().Round(0)
+}
+
+/******************************************************************************/
+
+// An entryQueue is a heap of entries by ascending next time instant.
+type entryQueue []*entry
+
+func (q entryQueue) Len() int {
+	return len(q)
+}
+
+func (q entryQueue) Less(i, j int) bool {
+	return q[i].next.Before(q[j].next)
+}
+
+func (q entryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *entryQueue) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *entryQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	e.index = -1
+	*q = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,330 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/scheduler_test.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+var everySecond = cronexpr.MustParse("* * * * * * *")
+
+// collect returns the time instants received from `c` until none is received
+// for a while.
+func collect(c <-chan time.Time) []time.Time {
+	var times []time.Time
+	for {
+		select {
+		case t := <-c:
+			times = append(times, t)
+		case <-time.After(100 * time.Millisecond):
+			sort.Slice(times, func(i, j int) bool {
+				return times[i].Before(times[j])
+			})
+			return times
+		}
+	}
+}
+
+// advance advances `clock` by `d` once the scheduler waits on it, so that it
+// does not miss the change.
+func advance(t *testing.T, clock *cronexpr.FakeClock, d time.Duration) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return clock.Timers() > 0
+	}, time.Second, time.Millisecond)
+	clock.Advance(d)
+}
+
+func TestScheduler(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base)
+	errs := make(chan error, 10)
+	s := New(Options{Clock: clock, ErrorHandler: func(name string, err error) {
+		errs <- err
+	}})
+
+	ran := make(chan time.Time, 10)
+	require.NoError(t, s.Add(Job{Name: "tick", Schedule: everySecond, Func: func(ctx context.Context, scheduled time.Time) error {
+		ran <- scheduled
+		return nil
+	}}))
+	require.NoError(t, s.Add(Job{Name: "boom", Schedule: everySecond, Func: func(ctx context.Context, scheduled time.Time) error {
+		panic("boom")
+	}}))
+	require.NoError(t, s.Add(Job{Name: "never", Schedule: cronexpr.MustParse("* * * * * 1980"), Func: func(ctx context.Context, scheduled time.Time) error {
+		return nil
+	}}))
+	assert.Error(t, s.Add(Job{Name: "tick", Schedule: everySecond, Func: func(ctx context.Context, scheduled time.Time) error {
+		return nil
+	}}))
+	assert.Error(t, s.Add(Job{Name: "incomplete"}))
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 3)
+	assert.Equal(t, "boom", jobs[0].Name)
+	assert.True(t, jobs[1].Next.IsZero())
+	assert.Equal(t, base.Add(time.Second), jobs[2].Next)
+
+	s.Start(context.Background())
+	advance(t, clock, time.Second)
+	assert.Equal(t, base.Add(time.Second), <-ran)
+	assert.EqualError(t, <-errs, "panic: boom")
+	advance(t, clock, time.Second)
+	assert.Equal(t, base.Add(2*time.Second), <-ran)
+
+	assert.True(t, s.Remove("tick"))
+	assert.False(t, s.Remove("tick"))
+	_, found := s.Job("tick")
+	assert.False(t, found)
+	s.Stop()
+}
+
+func TestSchedulerConcurrency(t *testing.T) {
+	clock := cronexpr.NewFakeClock(time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC))
+	s := New(Options{Clock: clock})
+	var skipped, queued int32
+	block := func(counter *int32, release <-chan struct{}) Func {
+		return func(ctx context.Context, scheduled time.Time) error {
+			atomic.AddInt32(counter, 1)
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil
+		}
+	}
+	require.NoError(t, s.Add(Job{Name: "skip", Schedule: everySecond, Func: block(&skipped, nil), Concurrency: Skip}))
+	release := make(chan struct{})
+	require.NoError(t, s.Add(Job{Name: "queue", Schedule: everySecond, Func: block(&queued, release), Concurrency: Queue}))
+
+	s.Start(context.Background())
+	advance(t, clock, time.Second)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&skipped) == 1 && atomic.LoadInt32(&queued) == 1
+	}, time.Second, time.Millisecond)
+	advance(t, clock, time.Second)
+	assert.Eventually(t, func() bool {
+		info, _ := s.Job("queue")
+		return info.Pending == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&skipped))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queued))
+	info, _ := s.Job("skip")
+	assert.Equal(t, JobInfo{Name: "skip", Next: info.Next, Running: 1}, info)
+	info, _ = s.Job("queue")
+	assert.Equal(t, 1, info.Running)
+
+	// Queued runs start as soon as the previous one completes
+	release <- struct{}{}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&queued) == 2
+	}, time.Second, time.Millisecond)
+	s.Stop()
+}
+
+func TestSchedulerWorkers(t *testing.T) {
+	clock := cronexpr.NewFakeClock(time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC))
+	s := New(Options{Clock: clock, Workers: 1})
+	var started int32
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, s.Add(Job{Name: name, Schedule: everySecond, Func: func(ctx context.Context, scheduled time.Time) error {
+			atomic.AddInt32(&started, 1)
+			<-ctx.Done()
+			return nil
+		}}))
+	}
+	s.Start(context.Background())
+	advance(t, clock, time.Second)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&started) == 1
+	}, time.Second, time.Millisecond)
+	advance(t, clock, time.Second)
+	assert.Never(t, func() bool {
+		return atomic.LoadInt32(&started) > 1
+	}, 100*time.Millisecond, time.Millisecond)
+	s.Stop()
+}
+
+func TestSchedulerSharedSchedule(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	// Shared by both schedulers and the test, see `go test -race`
+	lastDay := cronexpr.MustParse("0 0 L * *")
+	clocks := []*cronexpr.FakeClock{cronexpr.NewFakeClock(base), cronexpr.NewFakeClock(base)}
+	ran := make(chan time.Time, 24)
+	for _, clock := range clocks {
+		s := New(Options{Clock: clock})
+		require.NoError(t, s.Add(Job{Name: "monthly", Schedule: lastDay, Func: func(ctx context.Context, scheduled time.Time) error {
+			ran <- scheduled
+			return nil
+		}}))
+		s.Start(context.Background())
+		defer s.Stop()
+	}
+	for month := 1; month <= 12; month++ {
+		for _, clock := range clocks {
+			clock.Set(base.AddDate(0, month, 0))
+		}
+		lastDay.Next(base.AddDate(0, month, 0))
+		expected := time.Date(2013, time.Month(month+1), 0, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, []time.Time{expected, expected}, []time.Time{<-ran, <-ran})
+	}
+}
+
+func TestSchedulerMisfire(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 30, 0, 0, time.UTC)
+	hour := func(h int) time.Time {
+		return time.Date(2013, time.January, 1, h, 0, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		name        string
+		misfire     Misfire
+		maxMisfires int
+		expected    []time.Time
+	}{
+		{"run once", MisfireRunOnce, 0, []time.Time{hour(12)}},
+		{"skip", MisfireSkip, 0, nil},
+		{"run all", MisfireRunAll, 0, []time.Time{hour(8), hour(9), hour(10), hour(11), hour(12)}},
+		{"run all without limit", MisfireRunAll, -1, []time.Time{hour(8), hour(9), hour(10), hour(11), hour(12)}},
+		{"run all up to 3", MisfireRunAll, 3, []time.Time{hour(10), hour(11), hour(12)}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := New(Options{Clock: cronexpr.NewFakeClock(base)})
+			ran := make(chan time.Time, 10)
+			require.NoError(t, s.Add(Job{
+				Name:        "hourly",
+				Schedule:    cronexpr.MustParse("0 * * * *"),
+				Misfire:     c.misfire,
+				MaxMisfires: c.maxMisfires,
+				LastRun:     hour(7),
+				Func: func(ctx context.Context, scheduled time.Time) error {
+					ran <- scheduled
+					return nil
+				},
+			}))
+			s.Start(context.Background())
+			assert.Equal(t, c.expected, collect(ran))
+			info, _ := s.Job("hourly")
+			assert.Equal(t, hour(13), info.Next)
+			s.Stop()
+		})
+	}
+}
+
+func TestSchedulerMisfireLimit(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	s := New(Options{Clock: cronexpr.NewFakeClock(base)})
+	ran := make(chan time.Time, 200)
+	require.NoError(t, s.Add(Job{
+		Name:     "often",
+		Schedule: everySecond,
+		Misfire:  MisfireRunAll,
+		LastRun:  base.AddDate(0, 0, -7),
+		Func: func(ctx context.Context, scheduled time.Time) error {
+			ran <- scheduled
+			return nil
+		},
+	}))
+	s.Start(context.Background())
+	times := collect(ran)
+	require.Len(t, times, 100)
+	assert.Equal(t, base.Add(-99*time.Second), times[0])
+	assert.Equal(t, base, times[99])
+	s.Stop()
+}
+
+func TestSchedulerMisfireAsleep(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 30, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base)
+	s := New(Options{Clock: clock})
+	ran := make(chan time.Time, 10)
+	require.NoError(t, s.Add(Job{
+		Name:     "often",
+		Schedule: cronexpr.MustParse("*/10 * * * *"),
+		Func: func(ctx context.Context, scheduled time.Time) error {
+			ran <- scheduled
+			return nil
+		},
+	}))
+	s.Start(context.Background())
+
+	// On time
+	clock.Advance(10 * time.Minute)
+	assert.Equal(t, []time.Time{base.Add(10 * time.Minute)}, collect(ran))
+
+	// Woken up an hour late
+	clock.Advance(65 * time.Minute)
+	assert.Equal(t, []time.Time{base.Add(70 * time.Minute)}, collect(ran))
+	info, _ := s.Job("often")
+	assert.Equal(t, base.Add(80*time.Minute), info.Next)
+	s.Stop()
+}
+
+func TestSchedulerPending(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base.Add(-time.Second))
+	s := New(Options{Clock: clock})
+	started := make(chan time.Time, 10)
+	require.NoError(t, s.Add(Job{
+		Name:        "queue",
+		Schedule:    cronexpr.MustParse("* * * * *"),
+		Concurrency: Queue,
+		MaxPending:  2,
+		Func: func(ctx context.Context, scheduled time.Time) error {
+			started <- scheduled
+			<-ctx.Done()
+			return nil
+		},
+	}))
+	s.Start(context.Background())
+	clock.Advance(time.Second)
+	assert.Equal(t, base, <-started)
+
+	// Runs beyond MaxPending are skipped
+	for i := 1; i <= 3; i++ {
+		clock.Advance(time.Minute)
+		assert.Eventually(t, func() bool {
+			info, _ := s.Job("queue")
+			return info.Next.Equal(base.Add(time.Duration(i+1) * time.Minute))
+		}, time.Second, 10*time.Millisecond)
+	}
+	info, _ := s.Job("queue")
+	assert.Equal(t, 2, info.Pending)
+
+	// Pending runs are dropped once stopped
+	s.Stop()
+	info, _ = s.Job("queue")
+	assert.Equal(t, 0, info.Pending)
+	history, _ := s.History("queue")
+	outcomes := make(map[time.Time]Outcome)
+	for _, record := range history {
+		outcomes[record.Scheduled] = record.Outcome
+	}
+	assert.Equal(t, map[time.Time]Outcome{
+		base:                      Succeeded,
+		base.Add(time.Minute):     Canceled,
+		base.Add(2 * time.Minute): Canceled,
+		base.Add(3 * time.Minute): Skipped,
+	}, outcomes)
+}
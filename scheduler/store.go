@@ -0,0 +1,204 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/store.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/******************************************************************************/
+
+// A Status is the outcome of the last run of a job, as recorded by a JobStore.
+type Status int
+
+const (
+	// StatusIdle means the job has not run yet.
+	StatusIdle Status = iota
+	// StatusRunning means the last run is in progress, or was interrupted if
+	// the process is gone.
+	StatusRunning
+	// StatusSucceeded means the last run returned no error.
+	StatusSucceeded
+	// StatusFailed means the last run returned an error or panicked.
+	StatusFailed
+)
+
+var statusNames = map[Status]string{
+	StatusIdle:      "idle",
+	StatusRunning:   "running",
+	StatusSucceeded: "succeeded",
+	StatusFailed:    "failed",
+}
+
+func (status Status) String() string {
+	if name, found := statusNames[status]; found {
+		return name
+	}
+	return fmt.Sprintf("Status(%d)", int(status))
+}
+
+func (status Status) MarshalText() ([]byte, error) {
+	if name, found := statusNames[status]; found {
+		return []byte(name), nil
+	}
+	return nil, fmt.Errorf("invalid status %d", int(status))
+}
+
+func (status *Status) UnmarshalText(text []byte) error {
+	for s, name := range statusNames {
+		if name == string(text) {
+			*status = s
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid status %q", text)
+}
+
+// A JobState is what a JobStore records about a job.
+type JobState struct {
+	Name string `json:"name"`
+	// LastRun is the time instant of the last run of the job, the zero value
+	// if it has not run yet.
+	LastRun time.Time `json:"last_run"`
+	// NextRun is the next time instant the job was due when the state was
+	// recorded, the zero value if none.
+	NextRun time.Time `json:"next_run"`
+	Status  Status    `json:"status"`
+	// Owner is the identity of the scheduler which won the lock of the last
+	// run, if the scheduler has a Locker.
+	Owner string `json:"owner,omitempty"`
+}
+
+// A JobStore records the state of jobs, so that a scheduler resumes where a
+// previous one left off, see Options.Store. Implementations must be safe for
+// concurrent use.
+type JobStore interface {
+	// Load returns the state recorded for the job named `name`, and reports
+	// whether there is one.
+	Load(name string) (JobState, bool, error)
+	// Save records `state`, replacing the state of the same job, if any.
+	Save(state JobState) error
+}
+
+/******************************************************************************/
+
+// A MemoryStore is a JobStore which keeps states in memory, for the lifetime of
+// the process.
+type MemoryStore struct {
+	lock   sync.Mutex
+	states map[string]JobState
+}
+
+// NewMemoryStore returns a new, empty, MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]JobState)}
+}
+
+func (store *MemoryStore) Load(name string) (JobState, bool, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	state, found := store.states[name]
+	return state, found, nil
+}
+
+func (store *MemoryStore) Save(state JobState) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	store.states[state.Name] = state
+	return nil
+}
+
+/******************************************************************************/
+
+// A FileStore is a JobStore which keeps states in a JSON file, an object of job
+// states by name. The file is replaced atomically on each save, and is not
+// meant to be shared by concurrent processes.
+type FileStore struct {
+	lock sync.Mutex
+	path string
+}
+
+// NewFileStore returns a new FileStore keeping states in the file at `path`,
+// which is created on the first save if it does not exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (store *FileStore) Load(name string) (JobState, bool, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	states, err := store.read()
+	if err != nil {
+		return JobState{}, false, err
+	}
+	state, found := states[name]
+	return state, found, nil
+}
+
+func (store *FileStore) Save(state JobState) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	states, err := store.read()
+	if err != nil {
+		return err
+	}
+	states[state.Name] = state
+	return store.write(states)
+}
+
+func (store *FileStore) read() (map[string]JobState, error) {
+	states := make(map[string]JobState)
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return states, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("%s: %w", store.path, err)
+	}
+	return states, nil
+}
+
+func (store *FileStore) write(states map[string]JobState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), store.path)
+}
@@ -0,0 +1,101 @@
+/*!
+ * Project: github.com/WinnerSoftLab/cronexpr
+ * File: scheduler/store_test.go
+ * Version: 1.0
+ * License: pick the one which suits you best:
+ *   GPL v3 see <https://www.gnu.org/licenses/gpl.html>
+ *   APL v2 see <http://www.apache.org/licenses/LICENSE-2.0>
+ *
+ */
+
+package scheduler
+
+/******************************************************************************/
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/WinnerSoftLab/cronexpr"
+)
+
+/******************************************************************************/
+
+func TestStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	stores := map[string]JobStore{
+		"memory": NewMemoryStore(),
+		"file":   NewFileStore(path),
+	}
+	state := JobState{
+		Name:    "backup",
+		LastRun: time.Date(2013, time.January, 1, 2, 0, 0, 0, time.UTC),
+		NextRun: time.Date(2013, time.January, 2, 2, 0, 0, 0, time.UTC),
+		Status:  StatusFailed,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			_, found, err := store.Load("backup")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			require.NoError(t, store.Save(state))
+			require.NoError(t, store.Save(JobState{Name: "other", Status: StatusIdle}))
+			loaded, found, err := store.Load("backup")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, state, loaded)
+		})
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"status": "failed"`)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, _, err = NewFileStore(path).Load("backup")
+	assert.Error(t, err)
+}
+
+func TestSchedulerStore(t *testing.T) {
+	base := time.Date(2013, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := cronexpr.NewFakeClock(base.Add(-time.Second))
+	store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+	ran := make(chan time.Time, 10)
+	job := Job{
+		Name:     "hourly",
+		Schedule: cronexpr.MustParse("0 * * * *"),
+		Misfire:  MisfireRunAll,
+		Func: func(ctx context.Context, scheduled time.Time) error {
+			ran <- scheduled
+			return nil
+		},
+	}
+
+	s := New(Options{Clock: clock, Store: store})
+	require.NoError(t, s.Add(job))
+	s.Start(context.Background())
+	clock.Advance(time.Second)
+	assert.Equal(t, []time.Time{base}, collect(ran))
+	s.Stop()
+	state, found, err := store.Load("hourly")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, JobState{Name: "hourly", LastRun: base, NextRun: base.Add(time.Hour), Status: StatusSucceeded}, state)
+
+	// Restarted three hours later, the missed runs are caught up with
+	clock.Advance(3*time.Hour + time.Minute)
+	s = New(Options{Clock: clock, Store: store})
+	require.NoError(t, s.Add(job))
+	s.Start(context.Background())
+	assert.Equal(t, []time.Time{base.Add(time.Hour), base.Add(2 * time.Hour), base.Add(3 * time.Hour)}, collect(ran))
+	s.Stop()
+	state, _, _ = store.Load("hourly")
+	assert.Equal(t, base.Add(3*time.Hour), state.LastRun)
+}